@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// EachFuncResetter 回退通配步骤展开后所有函数变量的修改。
+type EachFuncResetter interface {
+	Resetter
+
+	// Elems 各元素的 FuncResetter，顺序同展开顺序，映射元素的顺序不固定。
+	Elems() []FuncResetter
+
+	// Check 检查各元素的调用是否满足期望，合并各元素的错误。
+	Check() error
+
+	// Verify 检查各元素的调用是否满足期望，不满足时通过 tb 报告错误。
+	Verify(tb testing.TB)
+}
+
+// eachResetter 通配步骤展开后各元素的修改。回退时先按相反顺序回退各元素，再回退通配步骤之前路径上的初始化。
+type eachResetter[R Resetter] struct {
+	once         sync.Once
+	elems        []R
+	restoreFuncs []func()
+}
+
+func (r *eachResetter[R]) Reset() {
+	r.once.Do(func() {
+		for i := len(r.elems) - 1; i >= 0; i-- {
+			r.elems[i].Reset()
+		}
+		for i := len(r.restoreFuncs) - 1; i >= 0; i-- {
+			r.restoreFuncs[i]()
+		}
+	})
+}
+
+func (r *eachResetter[R]) String() string {
+	lines := make([]string, len(r.elems))
+	for i, v := range r.elems {
+		lines[i] = fmt.Sprint(v)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type eachFuncResetter struct {
+	*eachResetter[FuncResetter]
+}
+
+func (r eachFuncResetter) Elems() []FuncResetter {
+	return append([]FuncResetter(nil), r.elems...)
+}
+
+func (r eachFuncResetter) Check() error {
+	errs := make([]error, len(r.elems))
+	for i, v := range r.elems {
+		errs[i] = v.Check()
+	}
+	return errors.Join(errs...)
+}
+
+func (r eachFuncResetter) Verify(tb testing.TB) {
+	tb.Helper()
+	for _, v := range r.elems {
+		v.Verify(tb)
+	}
+}
+
+// wildcard 第一个通配步骤的序号，没有时返回 -1。
+func (c *chainSetter) wildcard() int {
+	for i, v := range c.actions {
+		if v.typ == toEach || v.typ == toWhere {
+			return i
+		}
+	}
+	return -1
+}
+
+// expand 将通配步骤展开成各元素的 Index 或 MapValue 步骤，返回不含通配步骤的链，以及回退路径上初始化的函数。
+// readOnly 时同 Get 只读地路由，否则同 Set 按链的选项初始化通配步骤之前路径上的 nil 值。映射元素的顺序不固定。
+func (c *chainSetter) expand(readOnly bool) (chains []*chainSetter, restoreFuncs []func()) {
+	w := c.wildcard()
+	if w < 0 {
+		return []*chainSetter{c}, nil
+	}
+
+	callbackFuncs, restoreFuncs, value, trace := c.seekValue(*c.value, readOnly)
+
+	// 展开失败时，回退路径上的初始化。
+	defer func() {
+		if p := recover(); p != nil {
+			for i := len(restoreFuncs) - 1; i >= 0; i-- {
+				restoreFuncs[i]()
+			}
+			panic(p)
+		}
+	}()
+
+	// 使路径上的初始化生效，展开后的链将再次经过它们。
+	for i := len(callbackFuncs) - 1; i >= 0; i-- {
+		callbackFuncs[i]()
+	}
+
+	var predicate func(key, val any) bool
+	if c.actions[w].typ == toWhere {
+		predicate = c.actions[w].args[0].(func(key, val any) bool)
+		if predicate == nil {
+			panic(c.newChainError(trace.ops, w, value.Type(), ErrPredicateCannotBeNil))
+		}
+	}
+
+	var elems []*action
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			if predicate == nil || predicate(i, getSequenceIndex(value, i).Interface()) {
+				elems = append(elems, &action{toSeqElem, []any{i}})
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			if predicate == nil || predicate(iter.Key().Interface(), iter.Value().Interface()) {
+				elems = append(elems, &action{toMapValue, []any{iter.Key().Interface()}})
+			}
+		}
+	default:
+		panic(c.newChainError(trace.ops, w, value.Type(), ErrTargetIsNotIterable))
+	}
+
+	// 后续步骤中可能还有通配步骤，继续展开。
+	chains = make([]*chainSetter, 0, len(elems))
+	for _, v := range elems {
+		actions := make([]*action, 0, len(c.actions))
+		actions = append(actions, c.actions[:w]...)
+		actions = append(actions, v)
+		actions = append(actions, c.actions[w+1:]...)
+		subChains, subRestoreFuncs := (&chainSetter{c.value, actions, c.tb, c.group, c.options}).expand(readOnly)
+		chains = append(chains, subChains...)
+		restoreFuncs = append(restoreFuncs, subRestoreFuncs...)
+	}
+	return chains, restoreFuncs
+}
+
+// fanOut 展开通配步骤，对每个元素执行修改。某个元素修改失败时，回退已完成的修改。
+func fanOut[R Resetter](c *chainSetter, fn func(*chainSetter) R) *eachResetter[R] {
+	chains, restoreFuncs := c.expand(false)
+	r := &eachResetter[R]{elems: make([]R, 0, len(chains)), restoreFuncs: restoreFuncs}
+	defer func() {
+		if p := recover(); p != nil {
+			r.Reset()
+			panic(p)
+		}
+	}()
+	for _, v := range chains {
+		r.elems = append(r.elems, fn(v))
+	}
+	return r
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+// ChainOption 链式修改的选项。
+type ChainOption func(*chainOptions)
+
+type chainOptions struct {
+	noAutoAlloc bool
+}
+
+// NoAutoAlloc 路由时不再初始化路径上的 nil 指针、映射和接口，也不再创建路径中间不存在的映射键，
+// 而是以指出该步骤的 ChainError 报错。默认会初始化它们，并在回退时恢复。
+func NoAutoAlloc() ChainOption {
+	return func(o *chainOptions) {
+		o.noAutoAlloc = true
+	}
+}
+
+func newChainOptions(opts []ChainOption) *chainOptions {
+	o := &chainOptions{}
+	for _, v := range opts {
+		v(o)
+	}
+	return o
+}
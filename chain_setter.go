@@ -14,7 +14,9 @@ package modify_variables_temporarily
 
 import (
 	"reflect"
+	"runtime"
 	"strings"
+	"testing"
 )
 
 const (
@@ -23,15 +25,45 @@ const (
 	toStructFieldByName
 	toMapValue
 	toSeqElem
+	toAutoElem          // 当前变量是指针时解引用，直到不是指针为止。
+	toMapValueOrSeqElem // 根据当前变量的类型，获取映射的键值或序列的元素。
+	toEach              // 获取数组、切片或映射的所有元素。
+	toWhere             // 获取数组、切片或映射中满足条件的元素。
 )
 
 type ChainSetter interface {
 	Chainer
 	Setter
+	Getter
 }
 
 type actionType int
 
+func (t actionType) String() string {
+	switch t {
+	case toElem:
+		return "Elem"
+	case toStructField:
+		return "Field"
+	case toStructFieldByName:
+		return "FieldByName"
+	case toMapValue:
+		return "MapValue"
+	case toSeqElem:
+		return "Index"
+	case toAutoElem:
+		return "AutoElem"
+	case toMapValueOrSeqElem:
+		return "MapValueOrIndex"
+	case toEach:
+		return "Each"
+	case toWhere:
+		return "Where"
+	default:
+		return "Unknown"
+	}
+}
+
 type action struct {
 	typ  actionType
 	args []any
@@ -40,6 +72,9 @@ type action struct {
 type chainSetter struct {
 	value   *reflect.Value
 	actions []*action
+	tb      testing.TB
+	group   *Group
+	options *chainOptions
 }
 
 func (c *chainSetter) Elem() ChainSetter {
@@ -48,7 +83,7 @@ func (c *chainSetter) Elem() ChainSetter {
 	copy(actions, c.actions)
 	actions[len(c.actions)] = act
 	c.actions = append(c.actions, act)
-	return &chainSetter{c.value, actions}
+	return &chainSetter{c.value, actions, c.tb, c.group, c.options}
 }
 
 func (c *chainSetter) FieldByName(name string) ChainSetter {
@@ -57,7 +92,7 @@ func (c *chainSetter) FieldByName(name string) ChainSetter {
 	copy(actions, c.actions)
 	actions[len(c.actions)] = act
 	c.actions = append(c.actions, act)
-	return &chainSetter{c.value, actions}
+	return &chainSetter{c.value, actions, c.tb, c.group, c.options}
 }
 
 func (c *chainSetter) Field(index int) ChainSetter {
@@ -66,7 +101,7 @@ func (c *chainSetter) Field(index int) ChainSetter {
 	copy(actions, c.actions)
 	actions[len(c.actions)] = act
 	c.actions = append(c.actions, act)
-	return &chainSetter{c.value, actions}
+	return &chainSetter{c.value, actions, c.tb, c.group, c.options}
 }
 
 func (c *chainSetter) MapValue(key any) ChainSetter {
@@ -75,7 +110,7 @@ func (c *chainSetter) MapValue(key any) ChainSetter {
 	copy(actions, c.actions)
 	actions[len(c.actions)] = act
 	c.actions = append(c.actions, act)
-	return &chainSetter{c.value, actions}
+	return &chainSetter{c.value, actions, c.tb, c.group, c.options}
 }
 
 func (c *chainSetter) Index(index int) ChainSetter {
@@ -84,18 +119,146 @@ func (c *chainSetter) Index(index int) ChainSetter {
 	copy(actions, c.actions)
 	actions[len(c.actions)] = act
 	c.actions = append(c.actions, act)
-	return &chainSetter{c.value, actions}
+	return &chainSetter{c.value, actions, c.tb, c.group, c.options}
+}
+
+func (c *chainSetter) Each() ChainSetter {
+	act := &action{typ: toEach}
+	actions := make([]*action, len(c.actions)+1)
+	copy(actions, c.actions)
+	actions[len(c.actions)] = act
+	c.actions = append(c.actions, act)
+	return &chainSetter{c.value, actions, c.tb, c.group, c.options}
+}
+
+func (c *chainSetter) Where(predicate func(key, val any) bool) ChainSetter {
+	act := &action{toWhere, []any{predicate}}
+	actions := make([]*action, len(c.actions)+1)
+	copy(actions, c.actions)
+	actions[len(c.actions)] = act
+	c.actions = append(c.actions, act)
+	return &chainSetter{c.value, actions, c.tb, c.group, c.options}
 }
 
 func (c *chainSetter) Set(substitute any) Resetter {
+	if c.tb != nil {
+		c.tb.Helper()
+		return bindTB(c.tb, func() Resetter { return c.set(substitute) })
+	}
+	return c.register(c.set(substitute))
+}
+
+func (c *chainSetter) SetFuncOuts(outs []OutValue, opts ...FuncOption) FuncResetter {
+	if c.tb != nil {
+		c.tb.Helper()
+		r := bindTB(c.tb, func() FuncResetter { return c.setFuncOuts(outs, opts) })
+		verifyOnCleanup(c.tb, r)
+		return r
+	}
+	r := c.setFuncOuts(outs, opts)
+	c.register(r)
+	return r
+}
+
+func (c *chainSetter) SetEachFuncOuts(outs []OutValue, opts ...FuncOption) EachFuncResetter {
+	if c.tb != nil {
+		c.tb.Helper()
+		r := bindTB(c.tb, func() EachFuncResetter { return c.setEachFuncOuts(outs, opts) })
+		verifyOnCleanup(c.tb, r)
+		return r
+	}
+	r := c.setEachFuncOuts(outs, opts)
+	c.register(r)
+	return r
+}
+
+func (c *chainSetter) TrySet(substitute any) (Resetter, error) {
+	r, err := try(func() Resetter { return c.set(substitute) })
+	if err == nil {
+		c.register(r)
+	}
+	return r, err
+}
+
+func (c *chainSetter) TrySetFuncOuts(outs []OutValue, opts ...FuncOption) (FuncResetter, error) {
+	r, err := try(func() FuncResetter { return c.setFuncOuts(outs, opts) })
+	if err == nil {
+		c.register(r)
+		if c.tb != nil {
+			verifyOnCleanup(c.tb, r)
+		}
+	}
+	return r, err
+}
+
+func (c *chainSetter) TrySetEachFuncOuts(outs []OutValue, opts ...FuncOption) (EachFuncResetter, error) {
+	r, err := try(func() EachFuncResetter { return c.setEachFuncOuts(outs, opts) })
+	if err == nil {
+		c.register(r)
+		if c.tb != nil {
+			verifyOnCleanup(c.tb, r)
+		}
+	}
+	return r, err
+}
+
+func (c *chainSetter) DeleteKey(key any) Resetter {
+	if c.tb != nil {
+		c.tb.Helper()
+		return bindTB(c.tb, func() Resetter { return c.deleteKey(key) })
+	}
+	return c.register(c.deleteKey(key))
+}
+
+func (c *chainSetter) TryDeleteKey(key any) (Resetter, error) {
+	r, err := try(func() Resetter { return c.deleteKey(key) })
+	if err == nil {
+		c.register(r)
+	}
+	return r, err
+}
+
+func (c *chainSetter) Get() (any, error) {
+	return try(c.get)
+}
+
+// register 将修改登记到绑定的测试或修改组中。
+func (c *chainSetter) register(r Resetter) Resetter {
+	if c.tb != nil {
+		c.tb.Cleanup(r.Reset)
+	}
+	if c.group != nil {
+		c.group.Add(r)
+	}
+	return r
+}
+
+func (c *chainSetter) set(substitute any) Resetter {
 	if len(c.actions) <= 0 {
 		panic(ErrNoActions)
 	}
+	if c.wildcard() >= 0 {
+		return fanOut(c, func(e *chainSetter) Resetter { return e.set(substitute) })
+	}
 
-	callbackFuncs, restoreFuncs, value, _ := c.seekValue(*c.value)
+	callbackFuncs, restoreFuncs, value, trace := c.seekValue(*c.value, false)
+
+	// 修改失败时，回退路径上已做的修改。
+	defer func() {
+		if p := recover(); p != nil {
+			for i := len(restoreFuncs) - 1; i >= 0; i-- {
+				restoreFuncs[i]()
+			}
+			panic(p)
+		}
+	}()
 
 	old := value.Interface()
-	substituteValue := convertSubstituteToTypeValue(substitute, value.Type())
+	substituteValue, err := try(func() reflect.Value { return convertSubstituteToTypeValue(substitute, value.Type()) })
+	if err != nil {
+		panic(c.newChainError(trace.ops, len(c.actions), value.Type(), err))
+	}
+	m := describe(value.Type(), trace.path(value.Type()), old, substituteValue.Interface())
 	value.Set(substituteValue)
 	reset := generateSetOldFunc(value, old)
 	restoreFuncs = append(restoreFuncs, reset)
@@ -104,24 +267,58 @@ func (c *chainSetter) Set(substitute any) Resetter {
 		callbackFuncs[i]()
 	}
 
-	return newResetter(func() {
+	return newResetter(m, func() {
 		for i := len(restoreFuncs) - 1; i >= 0; i-- {
 			restoreFuncs[i]()
 		}
 	})
 }
 
-func (c *chainSetter) SetFuncOuts(outs []OutValue) Resetter {
+func (c *chainSetter) get() any {
+	if len(c.actions) <= 0 {
+		panic(ErrNoActions)
+	}
+	if c.wildcard() >= 0 {
+		chains, _ := c.expand(true)
+		values := make([]any, len(chains))
+		for i, v := range chains {
+			values[i] = v.get()
+		}
+		return values
+	}
+	_, _, value, _ := c.seekValue(*c.value, true)
+	return value.Interface()
+}
+
+func (c *chainSetter) setFuncOuts(outs []OutValue, opts []FuncOption) FuncResetter {
 	if len(c.actions) <= 0 {
 		panic(ErrNoActions)
 	}
+	if w := c.wildcard(); w >= 0 {
+		_, _, value, trace := c.seekValue(*c.value, true)
+		panic(c.newChainError(trace.ops, w, value.Type(), ErrWildcardFuncOuts))
+	}
+
+	callbackFuncs, restoreFuncs, value, trace := c.seekValue(*c.value, false)
+
+	// 修改失败时，回退路径上已做的修改。
+	defer func() {
+		if p := recover(); p != nil {
+			for i := len(restoreFuncs) - 1; i >= 0; i-- {
+				restoreFuncs[i]()
+			}
+			panic(p)
+		}
+	}()
 
-	callbackFuncs, restoreFuncs, value, typeChain := c.seekValue(*c.value)
 	if value.Kind() != reflect.Func {
-		panic(newTypeInvalid(ErrTargetIsNotFunc, typeChain))
+		panic(c.newChainError(trace.ops, len(c.actions), value.Type(), ErrTargetIsNotFunc))
+	}
+	if c.tb != nil {
+		opts = append(opts[:len(opts):len(opts)], withTB(c.tb))
 	}
 	old := value.Interface()
-	newFuncValue := makeFunc(value, outs)
+	newFuncValue, stub := makeFunc(value, outs, opts)
 	value.Set(newFuncValue)
 	reset := generateSetOldFunc(value, old)
 	restoreFuncs = append(restoreFuncs, reset)
@@ -130,63 +327,193 @@ func (c *chainSetter) SetFuncOuts(outs []OutValue) Resetter {
 		callbackFuncs[i]()
 	}
 
-	return newResetter(func() {
+	m := describeFuncOuts(value.Type(), trace.path(value.Type()), old, outs)
+	return &funcResetter{newResetter(m, func() {
+		for i := len(restoreFuncs) - 1; i >= 0; i-- {
+			restoreFuncs[i]()
+		}
+	}), stub}
+}
+
+func (c *chainSetter) setEachFuncOuts(outs []OutValue, opts []FuncOption) EachFuncResetter {
+	if len(c.actions) <= 0 {
+		panic(ErrNoActions)
+	}
+	return eachFuncResetter{fanOut(c, func(e *chainSetter) FuncResetter { return e.setFuncOuts(outs, opts) })}
+}
+
+func (c *chainSetter) deleteKey(key any) Resetter {
+	if c.wildcard() >= 0 {
+		return fanOut(c, func(e *chainSetter) Resetter { return e.deleteKey(key) })
+	}
+
+	callbackFuncs, restoreFuncs, value, trace := c.seekValue(*c.value, false)
+
+	// 删除失败时，回退路径上已做的修改。
+	defer func() {
+		if p := recover(); p != nil {
+			for i := len(restoreFuncs) - 1; i >= 0; i-- {
+				restoreFuncs[i]()
+			}
+			panic(p)
+		}
+	}()
+
+	if value.Kind() != reflect.Map {
+		panic(c.newChainError(trace.ops, len(c.actions), value.Type(), ErrTargetIsNotMap))
+	}
+	keyValue, valValue := getMapValueByKey(value, key)
+	if !valValue.IsValid() {
+		panic(c.newChainError(trace.ops, len(c.actions), value.Type(), newMapKeyNotFoundError(keyValue, value)))
+	}
+	value.SetMapIndex(keyValue, reflect.Value{})
+	restoreFuncs = append(restoreFuncs, func() { value.SetMapIndex(keyValue, valValue) })
+	m := describe(valValue.Type(), trace.path(value.Type(), valValue.Type()), valValue.Interface(),
+		label("<deleted>"))
+
+	for i := len(callbackFuncs) - 1; i >= 0; i-- {
+		callbackFuncs[i]()
+	}
+
+	return newResetter(m, func() {
 		for i := len(restoreFuncs) - 1; i >= 0; i-- {
 			restoreFuncs[i]()
 		}
 	})
 }
 
-func (c *chainSetter) seekValue(value reflect.Value) (
-	callbackFuncs, restoreFuncs []func(), lastValue reflect.Value, typeChain string) {
+// newChainError 生成第 step 个操作失败的错误。ops 是 seekValue 实际执行的操作。
+func (c *chainSetter) newChainError(ops []actionType, step int, typ reflect.Type, err error) error {
+	steps, index := c.steps(ops, step)
+	return &ChainError{Path: steps, StepIndex: index, Type: typ, Err: err}
+}
+
+// steps 将操作转换成 Step，并返回第 step 个操作对应的 Step 序号。
+// Path 隐含的解引用不是 Chainer 的方法，不转换，它失败时对应之后的步骤；[n] 按实际执行的操作转换成 MapValue 或 Index。
+func (c *chainSetter) steps(ops []actionType, step int) ([]Step, int) {
+	steps := make([]Step, 0, len(c.actions))
+	index := -1
+	for i, v := range c.actions {
+		if i == step {
+			index = len(steps)
+		}
+		if v.typ == toAutoElem || v == implicitElem {
+			continue
+		}
+		typ := v.typ
+		if typ == toMapValueOrSeqElem {
+			typ = toMapValue
+			if i < len(ops) && ops[i] != 0 {
+				typ = ops[i]
+			} else if _, ok := v.args[0].(int); ok {
+				typ = toSeqElem
+			}
+		}
+		var arg any
+		if len(v.args) > 0 {
+			arg = v.args[0]
+		}
+		steps = append(steps, Step{Op: typ.String(), Arg: arg})
+	}
+	if index < 0 {
+		index = len(steps)
+	}
+	return steps, index
+}
+
+// seekTrace seekValue 经过的类型与实际执行的操作。
+type seekTrace struct {
+	types []string
+	ops   []actionType // 与 actions 一一对应，未执行的是 0。
+}
+
+// path 将经过的类型与最终变量的类型连接成路径。
+func (t *seekTrace) path(types ...reflect.Type) string {
+	if len(t.types) <= 0 {
+		return typePath(types...)
+	}
+	return strings.Join(t.types, " -> ") + " -> " + typePath(types...)
+}
+
+// seekValue 根据操作路由到最终变量。readOnly 时不初始化 nil 值，键不存在时报错，也不生成回调和回退函数。
+// 设置了 NoAutoAlloc 时，同样不初始化 nil 值，路径中间的键不存在时报错。遇到通配步骤时停止，返回要展开的变量。
+func (c *chainSetter) seekValue(value reflect.Value, readOnly bool) (
+	callbackFuncs, restoreFuncs []func(), lastValue reflect.Value, trace *seekTrace) {
 
-	types := make([]string, 0, len(c.actions))
+	alloc := !readOnly && (c.options == nil || !c.options.noAutoAlloc)
+
+	trace = &seekTrace{types: make([]string, 0, len(c.actions)), ops: make([]actionType, len(c.actions))}
 	restoreFuncs = make([]func(), 0, len(c.actions)+1)
 	callbackFuncs = make([]func(), 0, len(c.actions)+1)
-	for _, v := range c.actions {
+
+	// 将路由中的错误包装成 ChainError，指出失败的步骤，并回退路径上已初始化的值。
+	var step int
+	var stepType reflect.Type
+	defer func() {
+		if p := recover(); p != nil {
+			for i := len(restoreFuncs) - 1; i >= 0; i-- {
+				restoreFuncs[i]()
+			}
+			err, ok := p.(error)
+			if _, isRuntimeError := p.(runtime.Error); !ok || isRuntimeError {
+				panic(p)
+			}
+			panic(c.newChainError(trace.ops, step, stepType, err))
+		}
+	}()
+
+	for i, v := range c.actions {
+		step, stepType = i, nil
+		if value.IsValid() {
+			stepType = value.Type()
+		}
 		if !value.IsValid() {
-			panic(newTypeInvalid(ErrCannotToNext, strings.Join(types, " -> ")))
+			panic(ErrCannotToNext)
+		}
+		typ := v.typ
+		if typ == toMapValueOrSeqElem {
+			typ = toSeqElem
+			if _, ok := v.args[0].(int); !ok || value.Kind() == reflect.Map {
+				typ = toMapValue
+			}
+		}
+		trace.ops[i] = typ
+		if typ != toAutoElem {
+			trace.types = append(trace.types, value.Type().String())
 		}
-		types = append(types, value.Type().String())
 
-		switch v.typ {
+		switch typ {
+		case toEach, toWhere:
+			lastValue = value
+			return
+		case toAutoElem:
+			for value.Kind() == reflect.Pointer {
+				trace.types = append(trace.types, value.Type().String())
+				elemValue, restore := elemOfPointer(value, alloc)
+				if !elemValue.IsValid() {
+					panic(newNilValueError(value.Type()))
+				}
+				if restore != nil {
+					restoreFuncs = append(restoreFuncs, restore)
+				}
+				value = elemValue
+			}
 		case toElem:
 			switch value.Kind() {
 			case reflect.Pointer:
-				elemValue := value.Elem()
+				elemValue, restore := elemOfPointer(value, alloc)
 				if !elemValue.IsValid() {
-					panic(newTypeInvalid(ErrCannotToNext, strings.Join(types, " -> ")))
+					panic(newNilValueError(value.Type()))
 				}
-
-				// 指向的类型是零值变量。
-				if elemValue.IsZero() {
-					switch elemValue.Kind() {
-					case reflect.Pointer: // 指针指向一个指针，指向的指针是 nil 值，那么初始化下它。
-						elemValue.Set(reflect.New(elemValue.Type().Elem())) // 给指向的指针变量分配空间。
-
-						// 回退修改时，把指向的指针变量重新设置成 nil。
-						tmpElemValue := elemValue
-						restoreFuncs = append(restoreFuncs, func() { tmpElemValue.SetZero() })
-					case reflect.Map: // 指针指向一个映射，该映射是 nil 值，那么初始化下它。
-						elemValue.Set(reflect.MakeMap(elemValue.Type())) // 给该映射变量分配空间。
-
-						// 回退修改时，把指向的指针变量重新设置成 nil。
-						tmpMapValue := elemValue
-						restoreFuncs = append(restoreFuncs, func() { tmpMapValue.SetZero() })
-					case reflect.Interface: // 初始化一个，避免获取的 Elem() 是 Invalid。
-						elemValue.Set(reflect.New(elemValue.Type()).Elem())
-
-						// 回退修改时，把它设置成 nil。
-						tmpInterfaceValue := elemValue
-						restoreFuncs = append(restoreFuncs, func() { tmpInterfaceValue.SetZero() })
-					}
+				if restore != nil {
+					restoreFuncs = append(restoreFuncs, restore)
 				}
 
 				value = elemValue
 			case reflect.Interface:
 				implValue := value.Elem()
 				if !implValue.IsValid() {
-					panic(newTypeInvalid(ErrCannotToNext, strings.Join(types, " -> ")))
+					panic(newNilValueError(value.Type()))
 				}
 
 				// 接口内部类型不可寻址，所以我们分配一个新的变量使用。同时复制下值。
@@ -194,7 +521,7 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 				newImplValue.Set(implValue)
 
 				// 如果是 nil 指针或映射，需要分配下内存。
-				if newImplValue.IsZero() {
+				if alloc && newImplValue.IsZero() {
 					switch newImplValue.Kind() {
 					case reflect.Pointer:
 						newImplValue.Set(reflect.New(newImplValue.Type().Elem()))
@@ -205,17 +532,19 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 					}
 				}
 
-				// 在回退修改时，把接口设置成原来的值。
-				tmpInterfaceValue := value
-				restoreFuncs = append(restoreFuncs, func() { tmpInterfaceValue.Set(implValue) })
+				if !readOnly {
+					// 在回退修改时，把接口设置成原来的值。
+					tmpInterfaceValue := value
+					restoreFuncs = append(restoreFuncs, func() { tmpInterfaceValue.Set(implValue) })
 
-				// 其它变量修改完时，把新建变量赋值给接口。
-				tmpInterfaceValue2 := value
-				callbackFuncs = append(callbackFuncs, func() { tmpInterfaceValue2.Set(newImplValue) })
+					// 其它变量修改完时，把新建变量赋值给接口。
+					tmpInterfaceValue2 := value
+					callbackFuncs = append(callbackFuncs, func() { tmpInterfaceValue2.Set(newImplValue) })
+				}
 
 				value = newImplValue
 			default:
-				panic(newTypeInvalid(ErrTargetIsNotPointerOrInterface, strings.Join(types, " -> ")))
+				panic(ErrTargetIsNotPointerOrInterface)
 			}
 		case toStructField:
 			switch value.Kind() {
@@ -223,10 +552,10 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 				index := v.args[0].(int)
 				fieldValue := getStructField(value, index)
 				if !fieldValue.IsValid() {
-					panic(newTypeInvalid(ErrCannotToNext, strings.Join(types, " -> ")))
+					panic(ErrCannotToNext)
 				}
 
-				if fieldValue.IsZero() {
+				if alloc && fieldValue.IsZero() {
 					switch fieldValue.Kind() {
 					case reflect.Map: // 分配一个新映射使用。
 						fieldValue.Set(reflect.MakeMap(fieldValue.Type()))
@@ -250,7 +579,7 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 
 				value = fieldValue
 			default:
-				panic(newTypeInvalid(ErrTargetIsNotStruct, strings.Join(types, " -> ")))
+				panic(ErrTargetIsNotStruct)
 			}
 		case toStructFieldByName:
 			switch value.Kind() {
@@ -261,10 +590,10 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 				}
 				fieldValue := getStructFieldByName(value, name)
 				if !fieldValue.IsValid() {
-					panic(newTypeInvalid(ErrCannotToNext, strings.Join(types, " -> ")))
+					panic(ErrCannotToNext)
 				}
 
-				if fieldValue.IsZero() {
+				if alloc && fieldValue.IsZero() {
 					switch fieldValue.Kind() {
 					case reflect.Pointer:
 						fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
@@ -288,16 +617,23 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 
 				value = fieldValue
 			default:
-				panic(newTypeInvalid(ErrTargetIsNotStruct, strings.Join(types, " -> ")))
+				panic(ErrTargetIsNotStruct)
 			}
 		case toMapValue:
 			switch value.Kind() {
 			case reflect.Map:
 				key := v.args[0]
+				// 路径表达式中的键是字面量，只允许不改变值的转换，避免整数被转换成字符。
+				if v.typ == toMapValueOrSeqElem && !losslessConvertible(reflect.ValueOf(key), value.Type().Key()) {
+					panic(newInvalidMapKeyError(reflect.TypeOf(key).String(), value.Type().String()))
+				}
 				keyValue, mapValValue := getMapValueByKey(value, key)
-				mapOldValValue := reflect.Zero(value.Type().Elem())
-				if mapValValue.IsValid() {
-					mapOldValValue = reflect.ValueOf(mapValValue.Interface())
+				// nil 映射没有键值，也无法写入键值。
+				if value.IsNil() {
+					panic(newNilValueError(value.Type()))
+				}
+				if !mapValValue.IsValid() && (readOnly || !alloc && i < len(c.actions)-1) {
+					panic(newMapKeyNotFoundError(keyValue, value))
 				}
 
 				// 映射键值存在，新建一个键值变量，然后复制下原键值。
@@ -308,7 +644,7 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 				}
 
 				// nil 指针和映射需要分配下内存。
-				if newMapValValue.IsZero() {
+				if alloc && newMapValValue.IsZero() {
 					switch newMapValValue.Kind() {
 					case reflect.Map:
 						newMapValValue.Set(reflect.MakeMap(newMapValValue.Type()))
@@ -319,16 +655,18 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 					}
 				}
 
-				// 映射的键值是不可寻址的，所以修改完其它变量时，回来设置下映射键值。
-				tmpMapValue := value
-				callbackFuncs = append(callbackFuncs, func() { tmpMapValue.SetMapIndex(keyValue, newMapValValue) })
+				if !readOnly {
+					// 映射的键值是不可寻址的，所以修改完其它变量时，回来设置下映射键值。
+					tmpMapValue := value
+					callbackFuncs = append(callbackFuncs, func() { tmpMapValue.SetMapIndex(keyValue, newMapValValue) })
 
-				// 回退修改时，把映射的键修改回来。
-				restoreFuncs = append(restoreFuncs, func() { tmpMapValue.SetMapIndex(keyValue, mapOldValValue) })
+					// 回退修改时，把映射的键修改回来，原本不存在的键则删除。
+					restoreFuncs = append(restoreFuncs, func() { restoreMapIndex(tmpMapValue, keyValue, mapValValue) })
+				}
 
 				value = newMapValValue
 			default:
-				panic(newTypeInvalid(ErrTargetIsNotMap, strings.Join(types, " -> ")))
+				panic(ErrTargetIsNotMap)
 			}
 		case toSeqElem:
 			switch value.Kind() {
@@ -336,11 +674,11 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 				index := v.args[0].(int)
 				elemValue := getSequenceIndex(value, index)
 				if !elemValue.IsValid() {
-					panic(newTypeInvalid(ErrCannotToNext, strings.Join(types, " -> ")))
+					panic(ErrCannotToNext)
 				}
 
 				// nil 指针和映射需要初始化下。
-				if elemValue.IsZero() {
+				if alloc && elemValue.IsZero() {
 					switch elemValue.Kind() {
 					case reflect.Pointer:
 						elemValue.Set(reflect.New(elemValue.Type().Elem()))
@@ -362,12 +700,45 @@ func (c *chainSetter) seekValue(value reflect.Value) (
 
 				value = elemValue
 			default:
-				panic(newTypeInvalid(ErrTargetIsNotSliceOrArray, strings.Join(types, " -> ")))
+				panic(ErrTargetIsNotSliceOrArray)
 			}
 		}
 	}
 	lastValue = value
-	typeChain = strings.Join(types, " -> ")
+
+	return
+}
+
+// elemOfPointer 获取指针指向的变量。alloc 且指向的变量是 nil 指针、映射或接口时，初始化它，并返回回退初始化的函数。
+func elemOfPointer(value reflect.Value, alloc bool) (elemValue reflect.Value, restore func()) {
+	elemValue = value.Elem()
+	if !elemValue.IsValid() {
+		return
+	}
+
+	// 指向的类型是零值变量。
+	if alloc && elemValue.IsZero() {
+		switch elemValue.Kind() {
+		case reflect.Pointer: // 指针指向一个指针，指向的指针是 nil 值，那么初始化下它。
+			elemValue.Set(reflect.New(elemValue.Type().Elem())) // 给指向的指针变量分配空间。
+
+			// 回退修改时，把指向的指针变量重新设置成 nil。
+			tmpElemValue := elemValue
+			restore = func() { tmpElemValue.SetZero() }
+		case reflect.Map: // 指针指向一个映射，该映射是 nil 值，那么初始化下它。
+			elemValue.Set(reflect.MakeMap(elemValue.Type())) // 给该映射变量分配空间。
+
+			// 回退修改时，把指向的指针变量重新设置成 nil。
+			tmpMapValue := elemValue
+			restore = func() { tmpMapValue.SetZero() }
+		case reflect.Interface: // 初始化一个，避免获取的 Elem() 是 Invalid。
+			elemValue.Set(reflect.New(elemValue.Type()).Elem())
+
+			// 回退修改时，把它设置成 nil。
+			tmpInterfaceValue := elemValue
+			restore = func() { tmpInterfaceValue.SetZero() }
+		}
+	}
 
 	return
 }
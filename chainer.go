@@ -28,4 +28,18 @@ type Chainer interface {
 
 	// Index 当前变量是数组或切片类型，获取它的一个元素的变量。
 	Index(index int) ChainSetter
+
+	// Each 当前变量是数组、切片或映射类型，获取它的所有元素或键值的变量。之后的修改作用于每个元素，返回的 Resetter 回退全部修改。
+	// 修改时按链的选项初始化 Each 之前路径上的 nil 值，读取时不初始化。
+	Each() ChainSetter
+
+	// Where 同 Each，但只获取 predicate 返回 true 的元素。key 是数组和切片的下标或映射的键，val 是元素的值。
+	// predicate 不能是 nil，否则修改时以 ErrPredicateCannotBeNil 报错。
+	Where(predicate func(key, val any) bool) ChainSetter
+
+	// DeleteKey 当前变量是映射类型，临时删除它的一个键。键须存在于映射中。
+	DeleteKey(key any) Resetter
+
+	// TryDeleteKey 同 DeleteKey，但以返回值代替 panic 报告错误。
+	TryDeleteKey(key any) (Resetter, error)
 }
@@ -15,6 +15,9 @@ package modify_variables_temporarily
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
 )
 
 var (
@@ -27,6 +30,7 @@ var (
 	ErrTargetIsNotSliceOrArray       = errors.New("[MVT]: target is neither a slice type nor an array type")
 	ErrTargetIsNotMap                = errors.New("[MVT]: target is not a map type")
 	ErrTargetIsNotStruct             = errors.New("[MVT]: target is not a struct type")
+	ErrTargetIsNotIterable           = errors.New("[MVT]: target is neither a slice, an array nor a map type")
 	ErrIncompatibleTypeAssignment    = errors.New("[MVT]: incompatible type assignment")
 	ErrStructFieldNameCannotBeEmpty  = errors.New("[MVT]: field name can not be empty")
 	ErrStructFieldNotFound           = errors.New("[MVT]: struct field not found")
@@ -35,15 +39,217 @@ var (
 	ErrInvalidMapKeyType             = errors.New("[MVT]: invalid map key type")
 	ErrNoActions                     = errors.New("[MVT]: no actions")
 	ErrIndexOutOfBound               = errors.New("[MVT]: index out of bound")
+	ErrResetFailed                   = errors.New("[MVT]: reset failed")
+	ErrMapKeyNotFound                = errors.New("[MVT]: map key not found")
+	ErrInvalidPath                   = errors.New("[MVT]: invalid path")
+	ErrArgsCountMismatch             = errors.New("[MVT]: args count mismatch")
+	ErrUnexpectedCall                = errors.New("[MVT]: unexpected call")
+	ErrExpectationNotMet             = errors.New("[MVT]: expectation not met")
+	ErrFuncHasNoErrorResult          = errors.New("[MVT]: function has no error result")
+	ErrFuncOutsMismatch              = errors.New("[MVT]: function outputs mismatch")
+	ErrFuncPatchUnsupported          = errors.New("[MVT]: function patching is unsupported on this platform")
+	ErrFuncCannotBePatched           = errors.New("[MVT]: function cannot be patched")
+	ErrSymbolNotFound                = errors.New("[MVT]: symbol not found")
+	ErrNilValue                      = errors.New("[MVT]: nil value on the chain")
+	ErrPredicateCannotBeNil          = errors.New("[MVT]: predicate can not be nil")
+	ErrWildcardFuncOuts              = errors.New("[MVT]: function outputs of a wildcard chain must be set by SetEachFuncOuts")
 )
 
-func newStructFieldNotFoundError(structName string, index int) error {
-	return fmt.Errorf("%w. struct %s does not have a field with index %d",
-		ErrStructFieldNotFound, structName, index)
+// OutValueMismatchError OutValue 的返回值与函数返回值的数量或类型不符。
+type OutValueMismatchError struct {
+	// OutIndex OutValue 在 outs 中的序号。
+	OutIndex int
+	// ResultIndex 不符的返回值序号。
+	ResultIndex int
+	// Expected 函数返回值的类型，返回值多余时是 nil。
+	Expected reflect.Type
+	// Given 给定值的类型，返回值缺少或给定 nil 时是 nil。
+	Given reflect.Type
+	// Func 函数类型。
+	Func reflect.Type
 }
 
-func newStructFieldNotFoundByNameError(structName string, name string) error {
-	return fmt.Errorf("%w. struct %s does not have a field with named %s", ErrStructFieldNotFound, structName, name)
+func (e *OutValueMismatchError) Error() string {
+	switch {
+	case e.Expected == nil:
+		return fmt.Sprintf("%v. outs[%d] result %d of type %v is extra, %s has %d results",
+			ErrFuncOutsMismatch, e.OutIndex, e.ResultIndex, e.Given, e.Func, e.Func.NumOut())
+	case e.Given == nil:
+		return fmt.Sprintf("%v. outs[%d] result %d of type %s is missing, %s has %d results",
+			ErrFuncOutsMismatch, e.OutIndex, e.ResultIndex, e.Expected, e.Func, e.Func.NumOut())
+	default:
+		return fmt.Sprintf("%v. outs[%d] result %d: %s cannot be assigned to %s",
+			ErrFuncOutsMismatch, e.OutIndex, e.ResultIndex, e.Given, e.Expected)
+	}
+}
+
+func (e *OutValueMismatchError) Unwrap() []error {
+	if e.Expected != nil && e.Given != nil {
+		return []error{ErrFuncOutsMismatch, ErrIncompatibleTypeAssignment}
+	}
+	return []error{ErrFuncOutsMismatch}
+}
+
+// PathError 路径表达式解析错误。
+type PathError struct {
+	// Expr 路径表达式。
+	Expr string
+	// Column 出错的列，从 1 开始。
+	Column int
+	// Msg 错误描述。
+	Msg string
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%v. %s at column %d\n\t%s\n\t%s^",
+		ErrInvalidPath, e.Msg, e.Column, e.Expr, strings.Repeat(" ", e.Column-1))
+}
+
+func (e *PathError) Unwrap() error { return ErrInvalidPath }
+
+// Step 链式调用中的一步。
+type Step struct {
+	// Op 操作名，如 Elem、FieldByName、Field、MapValue、Index、Each、Where。
+	Op string
+	// Arg 操作的参数，Elem 时是 nil。
+	Arg any
+}
+
+func (s Step) String() string {
+	if s.Arg == nil {
+		return fmt.Sprintf(".%s()", s.Op)
+	}
+	return fmt.Sprintf(".%s(%#v)", s.Op, s.Arg)
+}
+
+// ChainError 链式修改在某一步失败。
+type ChainError struct {
+	// Path 链式调用的所有步骤。
+	Path []Step
+	// StepIndex 失败的步骤序号，等于 len(Path) 表示路由完成后对最终变量的操作失败。
+	StepIndex int
+	// Type 失败时当前变量的类型。
+	Type reflect.Type
+	// Err 失败的原因。
+	Err error
+}
+
+func (e *ChainError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("Chain")
+	for i, v := range e.Path {
+		if i == e.StepIndex {
+			sb.WriteString(" >>> ")
+		}
+		sb.WriteString(v.String())
+	}
+	step := "the target"
+	if e.StepIndex < len(e.Path) {
+		step = fmt.Sprintf("step %d %s", e.StepIndex, e.Path[e.StepIndex])
+	}
+	return fmt.Sprintf("%v, %s failed on type %s, chain is %s", e.Err, step, typeString(e.Type), sb.String())
+}
+
+func (e *ChainError) Unwrap() error { return e.Err }
+
+// FieldNotFoundError 结构体没有指定的字段。
+type FieldNotFoundError struct {
+	// Struct 结构体类型。
+	Struct reflect.Type
+	// Name 字段名，按序号查找时是空串。
+	Name string
+	// Index 字段序号，按名称查找时是 -1。
+	Index int
+	// Fields 结构体的所有字段，按名称查找时按与 Name 的编辑距离从近到远排列，否则按声明顺序排列。
+	Fields []FieldInfo
+}
+
+// FieldInfo 结构体字段的描述。
+type FieldInfo struct {
+	Index    int
+	Name     string
+	Type     reflect.Type
+	Exported bool
+}
+
+func (f FieldInfo) String() string {
+	if f.Exported {
+		return fmt.Sprintf("#%d %s %s", f.Index, f.Name, f.Type)
+	}
+	return fmt.Sprintf("#%d %s %s (unexported)", f.Index, f.Name, f.Type)
+}
+
+func (e *FieldNotFoundError) Error() string {
+	var sb strings.Builder
+	if e.Name == "" {
+		_, _ = fmt.Fprintf(&sb, "%v. struct %s does not have a field with index %d", ErrStructFieldNotFound, e.Struct, e.Index)
+	} else {
+		_, _ = fmt.Fprintf(&sb, "%v. struct %s does not have a field with named %s", ErrStructFieldNotFound, e.Struct, e.Name)
+		if len(e.Fields) > 0 && isSimilar(e.Name, e.Fields[0].Name) {
+			_, _ = fmt.Fprintf(&sb, ", did you mean %s?", e.Fields[0].Name)
+		}
+	}
+	if len(e.Fields) > 0 {
+		sb.WriteString(" available fields:")
+		for _, v := range e.Fields {
+			_, _ = fmt.Fprintf(&sb, "\n\t%s", v)
+		}
+	}
+	return sb.String()
+}
+
+func (e *FieldNotFoundError) Unwrap() error { return ErrStructFieldNotFound }
+
+// IndexOutOfBoundError 下标越界。
+type IndexOutOfBoundError struct {
+	// Index 下标，可以是负数。
+	Index int
+	// Len 序列的长度。
+	Len int
+	// Type 序列的类型。
+	Type reflect.Type
+}
+
+func (e *IndexOutOfBoundError) Error() string {
+	return fmt.Sprintf("%v. index %d out of %s length %d", ErrIndexOutOfBound, e.Index, e.Type, e.Len)
+}
+
+func (e *IndexOutOfBoundError) Unwrap() error { return ErrIndexOutOfBound }
+
+// MapKeyNotFoundError 映射中没有指定的键。
+type MapKeyNotFoundError struct {
+	// Map 映射类型。
+	Map reflect.Type
+	// Key 查找的键。
+	Key any
+	// Keys 映射中与 Key 最接近的若干个键，按编辑距离从近到远排列。
+	Keys []any
+}
+
+func (e *MapKeyNotFoundError) Error() string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "%v. key %#v not found in %s", ErrMapKeyNotFound, e.Key, e.Map)
+	if len(e.Keys) > 0 {
+		if isSimilar(fmt.Sprint(e.Key), fmt.Sprint(e.Keys[0])) {
+			_, _ = fmt.Fprintf(&sb, ", did you mean %#v?", e.Keys[0])
+		}
+		keys := make([]string, len(e.Keys))
+		for i, v := range e.Keys {
+			keys[i] = fmt.Sprintf("%#v", v)
+		}
+		_, _ = fmt.Fprintf(&sb, " nearest keys: %s", strings.Join(keys, ", "))
+	}
+	return sb.String()
+}
+
+func (e *MapKeyNotFoundError) Unwrap() error { return ErrMapKeyNotFound }
+
+func newStructFieldNotFoundError(structType reflect.Type, index int) error {
+	return &FieldNotFoundError{Struct: structType, Index: index, Fields: structFields(structType, "")}
+}
+
+func newStructFieldNotFoundByNameError(structType reflect.Type, name string) error {
+	return &FieldNotFoundError{Struct: structType, Name: name, Index: -1, Fields: structFields(structType, name)}
 }
 
 func newIncompatibleTypeAssignmentError(typeName, toTypeName string) error {
@@ -54,10 +260,65 @@ func newInvalidMapKeyError(keyTypeName, toKeyTypeName string) error {
 	return fmt.Errorf("%w. key %s cannot use in %s", ErrInvalidMapKeyType, keyTypeName, toKeyTypeName)
 }
 
-func newIndexOutOfBoundError(index int, typeName string, length int) error {
-	return fmt.Errorf("%w. index %d out of %s length %d", ErrIndexOutOfBound, index, typeName, length)
+func newMapKeyNotFoundError(keyValue, mapValue reflect.Value) error {
+	return &MapKeyNotFoundError{Map: mapValue.Type(), Key: keyValue.Interface(), Keys: nearestMapKeys(mapValue, keyValue)}
+}
+
+func newIndexOutOfBoundError(index int, typ reflect.Type, length int) error {
+	return &IndexOutOfBoundError{Index: index, Len: length, Type: typ}
+}
+
+// newNilValueError 路由遇到 nil 值无法继续，同时包装 ErrCannotToNext 以兼容原有判断。
+func newNilValueError(typ reflect.Type) error {
+	return fmt.Errorf("%w. %w, %s is nil", ErrCannotToNext, ErrNilValue, typ)
+}
+
+func newFuncCannotBePatchedError(funcName, reason string) error {
+	return fmt.Errorf("%w. %s %s", ErrFuncCannotBePatched, funcName, reason)
+}
+
+func newSymbolNotFoundError(name, reason string) error {
+	return fmt.Errorf("%w. %s %s", ErrSymbolNotFound, name, reason)
+}
+
+func newArgsCountMismatchError(funcTypeName string, count int) error {
+	return fmt.Errorf("%w. %s cannot match %d args", ErrArgsCountMismatch, funcTypeName, count)
+}
+
+func newUnexpectedCallError(funcTypeName string, args []any) error {
+	return fmt.Errorf("%w. %s called with args %#v", ErrUnexpectedCall, funcTypeName, args)
+}
+
+func newExpectationNotMetError(funcTypeName string, problems, usages []string) error {
+	return fmt.Errorf("%w. %s: %s. %s", ErrExpectationNotMet, funcTypeName,
+		strings.Join(problems, "; "), strings.Join(usages, ", "))
+}
+
+func newFuncHasNoErrorResultError(funcTypeName string) error {
+	return fmt.Errorf("%w. the last result of %s is not error", ErrFuncHasNoErrorResult, funcTypeName)
+}
+
+func newFuncOutsCountMismatchError(funcTypeName string, count int) error {
+	return fmt.Errorf("%w. %s cannot return %d values", ErrFuncOutsMismatch, funcTypeName, count)
+}
+
+func newPathError(expr string, column int, format string, args ...any) error {
+	return &PathError{Expr: expr, Column: column, Msg: fmt.Sprintf(format, args...)}
 }
 
-func newTypeInvalid(err error, typ string) error {
-	return fmt.Errorf("%w, type chain is %s", err, typ)
+// try 执行 fn，将 fn 中抛出的错误作为返回值返回。非错误值及运行时错误将继续抛出。
+func try[R any](fn func() R) (r R, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			e, ok := p.(error)
+			if !ok {
+				panic(p)
+			}
+			if _, ok = e.(runtime.Error); ok {
+				panic(p)
+			}
+			err = e
+		}
+	}()
+	return fn(), nil
 }
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import "testing"
+
+const (
+	exhaustedCallOriginal exhaustedMode = iota
+	exhaustedPanic
+	exhaustedRepeatLast
+	exhaustedCycle
+)
+
+// FuncOption 替换函数变量的选项。
+type FuncOption func(*funcOptions)
+
+type exhaustedMode int
+
+type funcOptions struct {
+	exhausted exhaustedMode
+	tb        testing.TB
+	minCalls  int
+	maxCalls  int // 负数表示不限制。
+	consumed  bool
+	args      [][]any
+}
+
+// Strict 没有可用的返回值时，不再调用原函数，而是以 ErrUnexpectedCall panic。
+// 修改与测试绑定时，改为通过测试报告错误，并返回零值。
+func Strict() FuncOption {
+	return func(o *funcOptions) { o.exhausted = exhaustedPanic }
+}
+
+// RepeatLast 没有可用的返回值时，重复最后一次使用的返回值。从未使用过返回值时调用原函数。
+func RepeatLast() FuncOption {
+	return func(o *funcOptions) { o.exhausted = exhaustedRepeatLast }
+}
+
+// Cycle 没有可用的返回值时，恢复所有返回值的次数，从头开始选择。
+func Cycle() FuncOption {
+	return func(o *funcOptions) { o.exhausted = exhaustedCycle }
+}
+
+// ExpectCalls 期望函数恰好被调用 n 次。
+func ExpectCalls(n int) FuncOption {
+	return func(o *funcOptions) { o.minCalls, o.maxCalls = n, n }
+}
+
+// ExpectMinCalls 期望函数至少被调用 n 次。
+func ExpectMinCalls(n int) FuncOption {
+	return func(o *funcOptions) { o.minCalls = n }
+}
+
+// ExpectMaxCalls 期望函数至多被调用 n 次。
+func ExpectMaxCalls(n int) FuncOption {
+	return func(o *funcOptions) { o.maxCalls = n }
+}
+
+// ExpectConsumed 期望所有 OutValue 的返回次数都被用尽。
+func ExpectConsumed() FuncOption {
+	return func(o *funcOptions) { o.consumed = true }
+}
+
+// ExpectArgs 期望函数前几次调用的参数依次匹配 args，规则同 OutValue.Args。
+func ExpectArgs(args ...[]any) FuncOption {
+	return func(o *funcOptions) { o.args = append(o.args, args...) }
+}
+
+// withTB 绑定测试，用于报告错误。
+func withTB(tb testing.TB) FuncOption {
+	return func(o *funcOptions) { o.tb = tb }
+}
+
+func newFuncOptions(opts []FuncOption) *funcOptions {
+	o := &funcOptions{maxCalls: -1}
+	for _, v := range opts {
+		v(o)
+	}
+	return o
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import (
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+var (
+	patchMu sync.Mutex
+	// patches 各函数入口生效中的替换，后替换的在后，同时保持替换函数存活。
+	patches = make(map[unsafe.Pointer][]*funcPatch)
+)
+
+// funcPatch 一次函数入口的替换。
+type funcPatch struct {
+	entry       unsafe.Pointer
+	original    []byte
+	replacement any
+}
+
+// Func 将函数的入口改写为跳转到 replacement，使所有对 target 的调用都进入 replacement。目前仅支持 linux/amd64 和 linux/arm64。
+// target 要被替换的顶层函数或方法表达式，如 pkg.DoThing、(*T).Method，不能是 nil。
+// 被内联的调用不受影响，函数过小而可能被内联时返回错误，可对函数标注 //go:noinline，或以 -gcflags=all=-l 构建测试。
+// replacement 替换成的函数，类型须与 target 一致。
+// 同一函数可以多次替换，回退顺序不限，生效的总是未回退的替换中最后的一次。
+// 替换期间不能有其它协程正在执行 target，替换不是并发安全的。
+func Func(target, replacement any) Resetter {
+	if target == nil {
+		panic(ErrTargetCannotBeNil)
+	}
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Func {
+		panic(ErrTargetIsNotFunc)
+	}
+	if targetValue.IsNil() {
+		panic(ErrTargetCannotBeNilType)
+	}
+	if replacementType := reflect.TypeOf(replacement); replacementType != targetValue.Type() {
+		panic(newIncompatibleTypeAssignmentError(typeString(replacementType), targetValue.Type().String()))
+	}
+	if reflect.ValueOf(replacement).IsNil() {
+		panic(ErrTargetCannotBeNilType)
+	}
+
+	// 接口的数据字就是函数值，即指向 funcval 的指针。
+	funcval := (*[2]unsafe.Pointer)(unsafe.Pointer(&replacement))[1]
+	code := jumpCode(uintptr(funcval))
+	if code == nil {
+		panic(ErrFuncPatchUnsupported)
+	}
+	entry := targetValue.UnsafePointer()
+	checkPatchable(entry, len(code))
+
+	patchMu.Lock()
+	defer patchMu.Unlock()
+	p := &funcPatch{
+		entry:       entry,
+		original:    append([]byte(nil), unsafe.Slice((*byte)(entry), len(code))...),
+		replacement: replacement,
+	}
+	if err := writeCode(entry, code); err != nil {
+		panic(err)
+	}
+	patches[entry] = append(patches[entry], p)
+	m := describe(targetValue.Type(), runtime.FuncForPC(uintptr(entry)).Name(), target, replacement)
+	return newResetter(m, func() {
+		patchMu.Lock()
+		defer patchMu.Unlock()
+		p.restore()
+	})
+}
+
+// restore 回退替换。同一函数被多次替换且不是最后一次替换时，只将原入口交给之后的替换，由它回退时写回。
+func (p *funcPatch) restore() {
+	stack := patches[p.entry]
+	i := slices.Index(stack, p)
+	if i == len(stack)-1 {
+		if err := writeCode(p.entry, p.original); err != nil {
+			panic(err)
+		}
+	} else {
+		stack[i+1].original = p.original
+	}
+	if stack = slices.Delete(stack, i, i+1); len(stack) > 0 {
+		patches[p.entry] = stack
+	} else {
+		delete(patches, p.entry)
+	}
+}
+
+// checkPatchable 检查函数入口之后 size 个字节都属于该函数。
+func checkPatchable(entry unsafe.Pointer, size int) {
+	fn := runtime.FuncForPC(uintptr(entry))
+	if fn == nil {
+		panic(newFuncCannotBePatchedError("function", "is not found"))
+	}
+	name := fn.Name()
+	switch {
+	case strings.HasSuffix(name, "-fm"):
+		panic(newFuncCannotBePatchedError(name, "is a method value, use the method expression instead"))
+	case strings.HasPrefix(name, "reflect."):
+		panic(newFuncCannotBePatchedError(name, "is created by reflect"))
+	}
+	// 函数之后的对齐填充不属于函数，没有行号。
+	for i := range size {
+		pc := uintptr(unsafe.Add(entry, i))
+		f := runtime.FuncForPC(pc)
+		if _, line := fn.FileLine(pc); f == nil || f.Entry() != fn.Entry() || line <= 0 {
+			panic(newFuncCannotBePatchedError(name, "is too small to be patched, it may have been inlined, mark it //go:noinline"))
+		}
+	}
+}
+
+func typeString(typ reflect.Type) string {
+	if typ == nil {
+		return "nil"
+	}
+	return typ.String()
+}
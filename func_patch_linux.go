@@ -0,0 +1,36 @@
+//go:build amd64 || arm64
+
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// writeCode 将 code 写入 at 处的代码段。
+func writeCode(at unsafe.Pointer, code []byte) error {
+	pageSize := uintptr(syscall.Getpagesize())
+	offset := uintptr(at) % pageSize
+	pages := unsafe.Slice((*byte)(unsafe.Add(at, -int(offset))), (offset+uintptr(len(code))+pageSize-1)/pageSize*pageSize)
+	if err := syscall.Mprotect(pages, syscall.PROT_READ|syscall.PROT_WRITE|syscall.PROT_EXEC); err != nil {
+		return newFuncCannotBePatchedError("code page", "cannot be made writable: "+err.Error())
+	}
+	copy(unsafe.Slice((*byte)(at), len(code)), code)
+	clearCache(uintptr(at), uintptr(at)+uintptr(len(code)))
+	if err := syscall.Mprotect(pages, syscall.PROT_READ|syscall.PROT_EXEC); err != nil {
+		return newFuncCannotBePatchedError("code page", "cannot be made read-only: "+err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+// jumpCode 生成跳转到 funcval 指向函数的指令，funcval 同时作为闭包上下文放入 DX。
+func jumpCode(funcval uintptr) []byte {
+	return []byte{
+		0x48, 0xBA, // MOVQ $funcval, DX
+		byte(funcval), byte(funcval >> 8), byte(funcval >> 16), byte(funcval >> 24),
+		byte(funcval >> 32), byte(funcval >> 40), byte(funcval >> 48), byte(funcval >> 56),
+		0xFF, 0x22, // JMP (DX)
+	}
+}
+
+// clearCache amd64 的指令缓存与数据缓存保持一致，无需处理。
+func clearCache(uintptr, uintptr) {}
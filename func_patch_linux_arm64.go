@@ -0,0 +1,35 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import "encoding/binary"
+
+// jumpCode 生成跳转到 funcval 指向函数的指令，funcval 同时作为闭包上下文放入 R26。
+func jumpCode(funcval uintptr) []byte {
+	instructions := []uint32{
+		0xD2800000 | uint32(funcval&0xFFFF)<<5 | 26,             // MOVZ X26, #imm
+		0xF2A00000 | uint32(funcval>>16&0xFFFF)<<5 | 26,         // MOVK X26, #imm, LSL #16
+		0xF2C00000 | uint32(uint64(funcval)>>32&0xFFFF)<<5 | 26, // MOVK X26, #imm, LSL #32
+		0xF2E00000 | uint32(uint64(funcval)>>48&0xFFFF)<<5 | 26, // MOVK X26, #imm, LSL #48
+		0xF940035B, // LDR X27, [X26]
+		0xD61F0360, // BR X27
+	}
+	code := make([]byte, 0, len(instructions)*4)
+	for _, v := range instructions {
+		code = binary.LittleEndian.AppendUint32(code, v)
+	}
+	return code
+}
+
+// clearCache 将 [start, end) 的数据缓存写回，并使指令缓存失效，使写入的指令对取指可见。
+func clearCache(start, end uintptr)
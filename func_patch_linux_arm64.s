@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+#include "textflag.h"
+
+// func clearCache(start, end uintptr)
+TEXT ·clearCache(SB), NOSPLIT, $0-16
+	MOVD start+0(FP), R0
+	MOVD end+8(FP), R1
+	WORD $0xd53b0022 // MRS CTR_EL0, R2
+
+	// 数据缓存行大小是 4 << CTR_EL0.DminLine。
+	UBFX $16, R2, $4, R3
+	MOVD $4, R4
+	LSL  R3, R4, R4
+	SUB  $1, R4, R5
+	BIC  R5, R0, R3
+
+dcache:
+	WORD $0xd50b7b23 // DC CVAU, R3
+	ADD  R4, R3, R3
+	CMP  R1, R3
+	BLO  dcache
+	WORD $0xd5033b9f // DSB ISH
+
+	// 指令缓存行大小是 4 << CTR_EL0.IminLine。
+	AND  $15, R2, R3
+	MOVD $4, R4
+	LSL  R3, R4, R4
+	SUB  $1, R4, R5
+	BIC  R5, R0, R3
+
+icache:
+	WORD $0xd50b7523 // IC IVAU, R3
+	ADD  R4, R3, R3
+	CMP  R1, R3
+	BLO  icache
+	WORD $0xd5033b9f // DSB ISH
+	WORD $0xd5033fdf // ISB
+	RET
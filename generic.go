@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import "reflect"
+
+// Set 替换变量的值。类型由编译器检查，不会进行类型转换。
+// target 被替换的变量，不能是 nil。
+// substitute 替换成的值。
+func Set[T any](target *T, substitute T) Resetter {
+	return set(target, substitute, "")
+}
+
+// set 替换变量的值，path 是修改描述中的路径，空串表示变量的类型。
+func set[T any](target *T, substitute T, path string) Resetter {
+	if target == nil {
+		panic(ErrTargetCannotBeNil)
+	}
+	old := *target
+	*target = substitute
+	return newResetter(describe(reflect.TypeFor[T](), path, old, substitute), func() { *target = old })
+}
+
+// SetField 替换结构体字段的值。字段类型须与 F 完全一致。
+// target 被替换字段值的结构体变量，不能是 nil。
+// name 结构体字段的名称，可以是不导出的字段，但不能是空串。
+// substitute 替换成的值。
+func SetField[S, F any](target *S, name string, substitute F) Resetter {
+	if target == nil {
+		panic(ErrTargetCannotBeNil)
+	}
+	if len(name) <= 0 {
+		panic(ErrStructFieldNameCannotBeEmpty)
+	}
+	structValue := reflect.ValueOf(target).Elem()
+	if structValue.Kind() != reflect.Struct {
+		panic(ErrTargetIsNotStruct)
+	}
+	fieldValue := getStructFieldByName(structValue, name)
+	fieldType := reflect.TypeFor[F]()
+	if fieldValue.Type() != fieldType {
+		panic(newIncompatibleTypeAssignmentError(fieldType.String(), fieldValue.Type().String()))
+	}
+	return set((*F)(fieldValue.Addr().UnsafePointer()), substitute, typePath(structValue.Type(), fieldType))
+}
+
+// SetMapKey 替换映射中的某个键的值。回退时，原本不存在的键将被删除。
+// target 被替换元素的映射变量，不能是 nil。
+// key 将被修改映射值的键。
+// substitute 替换成的值。
+func SetMapKey[K comparable, V any](target map[K]V, key K, substitute V) Resetter {
+	if target == nil {
+		panic(ErrTargetCannotBeNilType)
+	}
+	old, ok := target[key]
+	target[key] = substitute
+	var oldValue any = label("<absent>")
+	if ok {
+		oldValue = old
+	}
+	m := describe(reflect.TypeFor[V](), typePath(reflect.TypeOf(target), reflect.TypeFor[V]()), oldValue, substitute)
+	return newResetter(m, func() {
+		if ok {
+			target[key] = old
+		} else {
+			delete(target, key)
+		}
+	})
+}
+
+// SetSliceElem 替换切片的元素值。
+// target 被替换元素的切片变量，不能是 nil。
+// index 被替换元素的下标，负数表示倒数。
+// substitute 替换成的值。
+func SetSliceElem[T any](target []T, index int, substitute T) Resetter {
+	if target == nil {
+		panic(ErrTargetCannotBeNilType)
+	}
+	length := len(target)
+	if index >= length || index < -length {
+		panic(newIndexOutOfBoundError(index, reflect.TypeOf(target), length))
+	}
+	if index < 0 {
+		index = length + index
+	}
+	return set(&target[index], substitute, typePath(reflect.TypeOf(target), reflect.TypeFor[T]()))
+}
+
+// SetFunc 替换函数变量。
+// target 被替换的函数变量，不能是 nil。
+// substitute 替换成的函数。
+func SetFunc[F any](target *F, substitute F) Resetter {
+	if reflect.TypeFor[F]().Kind() != reflect.Func {
+		panic(ErrTargetIsNotFunc)
+	}
+	return Set(target, substitute)
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import "reflect"
+
+// Getter 读取变量值。
+type Getter interface {
+	// Get 读取当前变量的值，不做任何修改。路径上遇到 nil 值或不存在的映射键时返回错误。
+	// 路径中有 Each 或 Where 时，返回所有匹配元素的值组成的 []any。
+	Get() (any, error)
+}
+
+// Peek 读取链式路由到的变量的值，并转换成 T 类型。读取失败或类型不符时 panic。
+// getter 如 Chain(&Data).Elem().FieldByName("m").MapValue("key")。
+func Peek[T any](getter Getter) T {
+	value, err := getter.Get()
+	if err != nil {
+		panic(err)
+	}
+	if value == nil {
+		var zero T
+		return zero
+	}
+	result, ok := value.(T)
+	if !ok {
+		panic(newIncompatibleTypeAssignmentError(reflect.TypeOf(value).String(), reflect.TypeFor[T]().String()))
+	}
+	return result
+}
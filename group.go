@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Group 修改组，收集多个修改，调用一次 Reset 即可按相反顺序全部回退。零值可直接使用。
+type Group struct {
+	mu        sync.Mutex
+	resetters []Resetter
+}
+
+// Add 将修改加入组中。
+func (g *Group) Add(resetters ...Resetter) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.resetters = append(g.resetters, resetters...)
+}
+
+// Var 同 Var，修改加入组中。
+func (g *Group) Var(target, substitute any) Resetter {
+	return g.add(Var(target, substitute))
+}
+
+// FieldByName 同 FieldByName，修改加入组中。
+func (g *Group) FieldByName(target any, name string, substitute any) Resetter {
+	return g.add(FieldByName(target, name, substitute))
+}
+
+// Field 同 Field，修改加入组中。
+func (g *Group) Field(target any, index int, substitute any) Resetter {
+	return g.add(Field(target, index, substitute))
+}
+
+// Elem 同 Elem，修改加入组中。
+func (g *Group) Elem(target any, index int, substitute any) Resetter {
+	return g.add(Elem(target, index, substitute))
+}
+
+// Map 同 Map，修改加入组中。
+func (g *Group) Map(target, key any, substitute any) Resetter {
+	return g.add(Map(target, key, substitute))
+}
+
+// MapDelete 同 MapDelete，修改加入组中。
+func (g *Group) MapDelete(target, key any) Resetter {
+	return g.add(MapDelete(target, key))
+}
+
+// FuncOuts 同 FuncOuts，修改加入组中。
+func (g *Group) FuncOuts(target any, outs []OutValue, opts ...FuncOption) FuncResetter {
+	r := FuncOuts(target, outs, opts...)
+	g.Add(r)
+	return r
+}
+
+// FuncErr 同 FuncErr，修改加入组中。
+func (g *Group) FuncErr(target any, err error, times int) FuncResetter {
+	r := FuncErr(target, err, times)
+	g.Add(r)
+	return r
+}
+
+// FuncReturns 同 FuncReturns，修改加入组中。
+func (g *Group) FuncReturns(target any, values ...any) FuncResetter {
+	r := FuncReturns(target, values...)
+	g.Add(r)
+	return r
+}
+
+// Func 同 Func，修改加入组中。
+func (g *Group) Func(target, replacement any) Resetter {
+	return g.add(Func(target, replacement))
+}
+
+// Snapshot 同 Snapshot，修改加入组中。
+func (g *Group) Snapshot(target any) Resetter {
+	return g.add(Snapshot(target))
+}
+
+// Chain 同 Chain，其 Set 和 SetFuncOuts 的修改加入组中。
+func (g *Group) Chain(target any, opts ...ChainOption) Chainer {
+	c := Chain(target, opts...).(*chainSetter)
+	c.group = g
+	return c
+}
+
+// Path 同 Path，其 Set 和 SetFuncOuts 的修改加入组中。
+func (g *Group) Path(target any, expr string, opts ...ChainOption) (ChainSetter, error) {
+	c, err := path(target, expr, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.group = g
+	return c, nil
+}
+
+// Reset 按相反顺序回退组中所有修改。某个回退 panic 时，其余回退仍会执行，最后以汇总的错误 panic。
+func (g *Group) Reset() {
+	if err := g.TryReset(); err != nil {
+		panic(err)
+	}
+}
+
+// TryReset 同 Reset，但以返回值代替 panic 报告错误。
+func (g *Group) TryReset() error {
+	g.mu.Lock()
+	resetters := g.resetters
+	g.resetters = nil
+	g.mu.Unlock()
+
+	var errs []error
+	for i := len(resetters) - 1; i >= 0; i-- {
+		if err := tryReset(resetters[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (g *Group) add(r Resetter) Resetter {
+	g.Add(r)
+	return r
+}
+
+// tryReset 执行回退，将回退中的 panic 转为错误。
+func tryReset(r Resetter) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			if e, ok := p.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%w. %v", ErrResetFailed, p)
+			}
+		}
+	}()
+	r.Reset()
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"text/tabwriter"
+)
+
+// Override 一个未回退的修改。
+type Override struct {
+	// Site 创建修改的位置，形如 file:line。
+	Site string
+	// Stack 创建修改时的调用栈。
+	Stack string
+	// Type 被修改的变量的类型。
+	Type reflect.Type
+	// Path 到达被修改的变量经过的类型，形如 *main.Data -> main.Data -> int。
+	Path string
+	// Old 原值，创建修改时以 %#v 格式化。
+	Old string
+	// New 新值，创建修改时以 %#v 格式化。
+	New string
+
+	resetter *resetter
+}
+
+// Active 返回所有未回退的修改，按创建顺序排列。
+func Active() []Override {
+	activeMu.Lock()
+	resetters := make([]*resetter, 0, len(active))
+	for r := range active {
+		resetters = append(resetters, r)
+	}
+	activeMu.Unlock()
+	slices.SortFunc(resetters, func(a, b *resetter) int { return cmp.Compare(a.id, b.id) })
+	overrides := make([]Override, len(resetters))
+	for i, r := range resetters {
+		overrides[i] = Override{
+			Site:     r.site(),
+			Stack:    r.stack(),
+			Type:     r.typ,
+			Path:     r.path,
+			Old:      r.old,
+			New:      r.new,
+			resetter: r,
+		}
+	}
+	return overrides
+}
+
+func (o Override) String() string {
+	return fmt.Sprintf("%s: %s %s -> %s", o.Site, o.Path, o.Old, o.New)
+}
+
+// Dump 以表格输出所有未回退的修改。
+func Dump(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SITE\tPATH\tOLD\tNEW")
+	for _, v := range Active() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", v.Site, v.Path, v.Old, v.New)
+	}
+	_ = tw.Flush()
+}
+
+// VerifyNone 在测试结束时检查测试期间创建的修改是否都已回退，未回退时通过 tb 报告它们及创建时的调用栈。
+// 应在测试开始时调用，检查在 T 绑定的修改回退之后进行。
+// 修改是全局登记的，不区分由哪个测试创建，测试期间其他测试创建且尚未回退的修改也会被报告，
+// 所以不能用于 t.Parallel() 的测试，也不能用于与之并行运行的测试，这时应使用 VerifyTestMain。
+func VerifyNone(tb testing.TB) {
+	tb.Helper()
+	before := make(map[*resetter]struct{})
+	for _, v := range Active() {
+		before[v.resetter] = struct{}{}
+	}
+	tb.Cleanup(func() {
+		var leaked []Override
+		for _, v := range Active() {
+			if _, ok := before[v.resetter]; !ok {
+				leaked = append(leaked, v)
+			}
+		}
+		if len(leaked) > 0 {
+			tb.Errorf("%s", formatLeaked(leaked))
+		}
+	})
+}
+
+// VerifyTestMain 运行测试，结束后仍有未回退的修改时打印它们并以非零状态退出，用于 TestMain。
+//
+//	func TestMain(m *testing.M) { mvt.VerifyTestMain(m) }
+func VerifyTestMain(m *testing.M) {
+	code := m.Run()
+	if leaked := Active(); len(leaked) > 0 {
+		_, _ = fmt.Fprintln(os.Stderr, formatLeaked(leaked))
+		if code == 0 {
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+func formatLeaked(leaked []Override) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "[MVT]: %d modifications were not reset", len(leaked))
+	for _, v := range leaked {
+		_, _ = fmt.Fprintf(&sb, "\n\ncreated at %s\n%s", v.Site, v.Stack)
+	}
+	return sb.String()
+}
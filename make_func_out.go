@@ -13,58 +13,392 @@
 package modify_variables_temporarily
 
 import (
+	"fmt"
 	"reflect"
-	"sync/atomic"
+	"sync"
+	"testing"
+	"time"
 )
 
 // OutValue 函数返回值。
+// 每次调用时，按声明顺序选择第一个次数未用尽且参数匹配的 OutValue，都不满足时调用原函数，可通过 FuncOption 改变该行为。
 type OutValue struct {
-	// Values 函数调用的返回值
+	// Values 函数调用的返回值，数量须与函数返回值一致，类型须可以赋值或转换成返回值类型，为空时返回零值。
+	// 替换函数时将检查 Values，不符合时以 *OutValueMismatchError panic。
 	Values []any
 	// Times 每组返回值返回次数。0 和 1 表示仅返回一次。
 	Times int
+	// Args 参数匹配规则，nil 表示匹配任意参数，否则数量须与函数参数一致。
+	// 元素可以是 Matcher、func(any) bool，或者是与参数比较相等的值。
+	// 函数是可变参数时，规则对应展开后的参数，如 func(string, ...any) 的 Args 可以是 []any{"%d", 1}。
+	Args []any
+	// Do 根据调用参数计算返回值，不是 nil 时代替 Values。返回值的处理同 Values。可变参数将被展开。
+	Do func(args []any) []any
+	// WaitFor 不是 nil 时，返回前阻塞直到它可读或被关闭，如 ctx.Done()。
+	WaitFor <-chan struct{}
+	// Delay 返回前睡眠的时间，在 WaitFor 之后。
+	Delay time.Duration
+	// Panic 不是 nil 时，在 WaitFor 和 Delay 之后以该值 panic，代替返回。
+	Panic any
 }
 
-func makeFunc(funcValue reflect.Value, outs []OutValue) reflect.Value {
+// FuncResetter 回退函数变量的修改，并记录替换后函数的调用。并发调用是安全的。
+type FuncResetter interface {
+	Resetter
+
+	// Calls 每次调用的参数，可变参数将被展开。
+	Calls() [][]any
+
+	// CallCount 调用次数。
+	CallCount() int
+
+	// LastArgs 最后一次调用的参数，未被调用时返回 nil。
+	LastArgs() []any
+
+	// Outs 每次调用的返回值，调用未返回时对应元素是 nil。
+	Outs() [][]any
+
+	// Check 检查调用是否满足 ExpectCalls 等选项设置的期望，不满足时返回的错误中包含各 OutValue 的使用次数。
+	Check() error
+
+	// Verify 检查调用是否满足期望，不满足时通过 tb 报告错误。
+	Verify(tb testing.TB)
+
+	// Original 被替换的原函数，类型同目标函数。目标是方法值时，可以通过它调用原接收者的方法。
+	Original() any
+}
+
+type funcResetter struct {
+	Resetter
+	*funcStub
+}
+
+// String 同 Resetter 的 String，嵌入接口不会提升该方法。
+func (r *funcResetter) String() string { return fmt.Sprint(r.Resetter) }
+
+// funcStub 替换后的函数的调用记录与期望。
+type funcStub struct {
+	*funcRecorder
+	funcType reflect.Type
+	original reflect.Value
+	outs     *funcOuts
+	options  *funcOptions
+	args     [][]Matcher
+}
+
+func (s *funcStub) Check() error {
+	calls := s.Calls()
+	count := len(calls)
+	var problems []string
+	switch o := s.options; {
+	case o.minCalls == o.maxCalls && count != o.minCalls:
+		problems = append(problems, fmt.Sprintf("expect %d calls, got %d", o.minCalls, count))
+	case count < o.minCalls:
+		problems = append(problems, fmt.Sprintf("expect at least %d calls, got %d", o.minCalls, count))
+	case o.maxCalls >= 0 && count > o.maxCalls:
+		problems = append(problems, fmt.Sprintf("expect at most %d calls, got %d", o.maxCalls, count))
+	}
+	for i, v := range s.args {
+		if i >= count {
+			problems = append(problems, fmt.Sprintf("call %d was not made", i))
+		} else if !matchArgs(v, calls[i]) {
+			problems = append(problems, fmt.Sprintf("call %d args %#v not match", i, calls[i]))
+		}
+	}
+
+	s.outs.mu.Lock()
+	usages := make([]string, len(s.outs.outs))
+	for i, v := range s.outs.outs {
+		usages[i] = fmt.Sprintf("outs[%d] used %d of %d times", i, v.used, v.total)
+		if s.options.consumed && v.used < v.total {
+			problems = append(problems, usages[i])
+		}
+	}
+	s.outs.mu.Unlock()
+
+	if len(problems) <= 0 {
+		return nil
+	}
+	return newExpectationNotMetError(s.funcType.String(), problems, usages)
+}
+
+func (s *funcStub) Original() any { return s.original.Interface() }
+
+func (s *funcStub) Verify(tb testing.TB) {
+	tb.Helper()
+	if err := s.Check(); err != nil {
+		tb.Errorf("%v", err)
+	}
+}
+
+// funcCall 函数的一次调用。
+type funcCall struct {
+	args []any
+	outs []any
+}
+
+// funcRecorder 记录函数调用。
+type funcRecorder struct {
+	mu    sync.Mutex
+	calls []*funcCall
+}
+
+func (r *funcRecorder) Calls() [][]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([][]any, len(r.calls))
+	for i, v := range r.calls {
+		result[i] = append([]any(nil), v.args...)
+	}
+	return result
+}
+
+func (r *funcRecorder) CallCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+func (r *funcRecorder) LastArgs() []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.calls) <= 0 {
+		return nil
+	}
+	return append([]any(nil), r.calls[len(r.calls)-1].args...)
+}
+
+func (r *funcRecorder) Outs() [][]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([][]any, len(r.calls))
+	for i, v := range r.calls {
+		if v.outs != nil {
+			result[i] = append([]any(nil), v.outs...)
+		}
+	}
+	return result
+}
+
+// record 记录一次调用。
+func (r *funcRecorder) record(funcType reflect.Type, ins []reflect.Value) *funcCall {
+	call := &funcCall{args: expandArgs(funcType, ins)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+	return call
+}
+
+// recordOuts 记录一次调用的返回值。
+func (r *funcRecorder) recordOuts(call *funcCall, outs []reflect.Value) {
+	values := valuesToInterfaces(outs)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	call.outs = values
+}
+
+// funcOut 生成好的一组函数返回值。
+type funcOut struct {
+	index    int // 在 outs 中的序号。
+	values   []reflect.Value
+	times    int // 剩余返回次数。
+	total    int // 总返回次数。
+	used     int // 已使用次数。
+	matchers []Matcher
+	do       func(args []any) []any
+	waitFor  <-chan struct{}
+	delay    time.Duration
+	panic    any
+}
+
+// funcOuts 按声明顺序选择函数返回值。
+type funcOuts struct {
+	mu        sync.Mutex
+	outs      []*funcOut
+	last      *funcOut
+	exhausted exhaustedMode
+}
+
+// next 选择第一个次数未用尽且参数匹配的返回值，没有时按用尽模式处理，仍没有时返回 nil。
+func (o *funcOuts) next(args []any) *funcOut {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := o.match(args)
+	if out == nil {
+		switch o.exhausted {
+		case exhaustedRepeatLast:
+			out = o.last
+		case exhaustedCycle:
+			for _, v := range o.outs {
+				v.times = v.total
+			}
+			out = o.match(args)
+		}
+	}
+	if out != nil {
+		out.used++
+		o.last = out
+	}
+	return out
+}
+
+func (o *funcOuts) match(args []any) *funcOut {
+	for _, v := range o.outs {
+		if v.times > 0 && matchArgs(v.matchers, args) {
+			v.times--
+			return v
+		}
+	}
+	return nil
+}
+
+func makeFunc(funcValue reflect.Value, outs []OutValue, opts []FuncOption) (reflect.Value, *funcStub) {
 	funcType := funcValue.Type()
-	outValues := generateFuncOutValues(funcType, outs)
-	length := int64(len(outValues))
-	count := int64(-1)
+	options := newFuncOptions(opts)
+	generatedOuts := &funcOuts{outs: generateFuncOutValues(funcType, outs), exhausted: options.exhausted}
+	recorder := &funcRecorder{}
 	keptFuncValue := reflect.ValueOf(funcValue.Interface())
+	stub := &funcStub{
+		funcRecorder: recorder,
+		funcType:     funcType,
+		original:     keptFuncValue,
+		outs:         generatedOuts,
+		options:      options,
+		args:         make([][]Matcher, len(options.args)),
+	}
+	for i, v := range options.args {
+		stub.args[i] = newArgMatchers(funcType, v)
+	}
 	return reflect.MakeFunc(funcType, func(ins []reflect.Value) []reflect.Value {
-		index := atomic.AddInt64(&count, 1)
-		if index < length {
-			return outValues[index]
+		call := recorder.record(funcType, ins)
+		var result []reflect.Value
+		if out := generatedOuts.next(call.args); out != nil {
+			if out.waitFor != nil {
+				<-out.waitFor
+			}
+			if out.delay > 0 {
+				time.Sleep(out.delay)
+			}
+			if out.panic != nil {
+				panic(out.panic)
+			}
+			if out.do != nil {
+				result = convertFuncOutValues(funcType, out.index, out.do(call.args))
+			} else {
+				result = out.values
+			}
+		} else if options.exhausted == exhaustedPanic {
+			err := newUnexpectedCallError(funcType.String(), call.args)
+			if options.tb == nil {
+				panic(err)
+			}
+			options.tb.Errorf("%v", err)
+			result = zeroFuncOutValues(funcType)
+		} else if funcType.IsVariadic() {
+			result = keptFuncValue.CallSlice(ins)
 		} else {
-			return keptFuncValue.Call(ins)
+			result = keptFuncValue.Call(ins)
 		}
-	})
+		recorder.recordOuts(call, result)
+		return result
+	}), stub
 }
 
-func generateFuncOutValues(funcType reflect.Type, outs []OutValue) [][]reflect.Value {
-	result := make([][]reflect.Value, 0, len(outs))
-	numOut := funcType.NumOut()
-	for _, v := range outs {
-		out := make([]reflect.Value, 0, numOut)
-		for index, value := range v.Values {
-			if index >= numOut {
-				break
-			}
-			outValueType := funcType.Out(index)
-			newOutValue := reflect.New(outValueType).Elem()
-			newOutValue.Set(convertSubstituteToTypeValue(value, outValueType))
-			out = append(out, newOutValue)
-		}
-		for len(out) < numOut {
-			outValueType := funcType.Out(len(out) - 1)
-			out = append(out, reflect.Zero(outValueType))
+func generateFuncOutValues(funcType reflect.Type, outs []OutValue) []*funcOut {
+	result := make([]*funcOut, 0, len(outs))
+	for i, v := range outs {
+		var out []reflect.Value
+		if v.Do == nil && v.Panic == nil {
+			out = convertFuncOutValues(funcType, i, v.Values)
 		}
 		if v.Times <= 0 {
 			v.Times = 1
 		}
-		for i := 0; i < v.Times; i++ {
-			result = append(result, out)
+		result = append(result, &funcOut{
+			index:    i,
+			values:   out,
+			times:    v.Times,
+			total:    v.Times,
+			matchers: newArgMatchers(funcType, v.Args),
+			do:       v.Do,
+			waitFor:  v.WaitFor,
+			delay:    v.Delay,
+			panic:    v.Panic,
+		})
+	}
+	return result
+}
+
+// convertFuncOutValues 将 outs[outIndex] 的 values 转换成函数返回值类型。values 为空时返回零值。
+func convertFuncOutValues(funcType reflect.Type, outIndex int, values []any) []reflect.Value {
+	if len(values) <= 0 {
+		return zeroFuncOutValues(funcType)
+	}
+	numOut := funcType.NumOut()
+	for index := range max(numOut, len(values)) {
+		mismatch := &OutValueMismatchError{OutIndex: outIndex, ResultIndex: index, Func: funcType}
+		if index < numOut {
+			mismatch.Expected = funcType.Out(index)
+		}
+		if index < len(values) && values[index] != nil {
+			mismatch.Given = reflect.TypeOf(values[index])
 		}
+		switch {
+		case index >= numOut, index >= len(values):
+			panic(mismatch)
+		case mismatch.Given != nil && !losslessConvertible(reflect.ValueOf(values[index]), mismatch.Expected):
+			panic(mismatch)
+		}
+	}
+	out := make([]reflect.Value, numOut)
+	for index, value := range values {
+		outValueType := funcType.Out(index)
+		newOutValue := reflect.New(outValueType).Elem()
+		newOutValue.Set(convertSubstituteToTypeValue(value, outValueType))
+		out[index] = newOutValue
+	}
+	return out
+}
+
+// losslessConvertible value 可以赋值给 typ 类型，或转换成 typ 类型后值不变。
+// 数值之间只允许不溢出、不截断的转换，浮点数之间允许损失精度，整数不能转换成字符串。
+func losslessConvertible(value reflect.Value, typ reflect.Type) bool {
+	valueType := value.Type()
+	switch {
+	case valueType.AssignableTo(typ):
+		return true
+	case !valueType.ConvertibleTo(typ):
+		return false
+	case isFloat(valueType.Kind()) && isFloat(typ.Kind()):
+		return !reflect.Zero(typ).OverflowFloat(value.Float())
+	case isNumeric(valueType.Kind()) && isNumeric(typ.Kind()):
+		return typ.ConvertibleTo(valueType) && value.Convert(typ).Convert(valueType).Equal(value)
+	default:
+		return valueType.Kind() == typ.Kind()
+	}
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Complex128
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// zeroFuncOutValues 生成函数返回值类型的零值。
+func zeroFuncOutValues(funcType reflect.Type) []reflect.Value {
+	out := make([]reflect.Value, funcType.NumOut())
+	for i := range out {
+		out[i] = reflect.Zero(funcType.Out(i))
+	}
+	return out
+}
+
+func valuesToInterfaces(values []reflect.Value) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v.Interface()
 	}
 	return result
 }
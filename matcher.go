@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import "reflect"
+
+// Matcher 匹配函数调用的一个参数。
+type Matcher interface {
+	// Match 参数是否匹配。
+	Match(arg any) bool
+}
+
+// MatcherFunc 函数形式的 Matcher。
+type MatcherFunc func(arg any) bool
+
+func (f MatcherFunc) Match(arg any) bool { return f(arg) }
+
+// Any 匹配任意参数。
+func Any() Matcher { return MatcherFunc(func(any) bool { return true }) }
+
+// Eq 匹配与 v 深度相等的参数，不进行类型转换。
+func Eq(v any) Matcher { return MatcherFunc(func(arg any) bool { return reflect.DeepEqual(v, arg) }) }
+
+// newArgMatchers 根据参数规则生成匹配器。
+// 规则元素可以是 Matcher、func(any) bool，或者是与参数比较相等的值，该值将转换成参数的类型。
+// 函数是可变参数时，规则对应展开后的参数，数量不少于固定参数的数量。
+func newArgMatchers(funcType reflect.Type, args []any) []Matcher {
+	if args == nil {
+		return nil
+	}
+	numIn := funcType.NumIn()
+	if funcType.IsVariadic() && len(args) < numIn-1 || !funcType.IsVariadic() && len(args) != numIn {
+		panic(newArgsCountMismatchError(funcType.String(), len(args)))
+	}
+	matchers := make([]Matcher, len(args))
+	for i, v := range args {
+		switch m := v.(type) {
+		case Matcher:
+			matchers[i] = m
+		case func(any) bool:
+			matchers[i] = MatcherFunc(m)
+		default:
+			matchers[i] = Eq(convertSubstituteToTypeValue(v, argType(funcType, i)).Interface())
+		}
+	}
+	return matchers
+}
+
+// argType 展开可变参数后第 i 个参数的类型。
+func argType(funcType reflect.Type, i int) reflect.Type {
+	if numIn := funcType.NumIn(); funcType.IsVariadic() && i >= numIn-1 {
+		return funcType.In(numIn - 1).Elem()
+	}
+	return funcType.In(i)
+}
+
+// expandArgs 将参数转换成 any，函数是可变参数时展开最后的切片。
+func expandArgs(funcType reflect.Type, ins []reflect.Value) []any {
+	if !funcType.IsVariadic() {
+		return valuesToInterfaces(ins)
+	}
+	fixed := ins[:len(ins)-1]
+	variadic := ins[len(ins)-1]
+	args := make([]any, 0, len(fixed)+variadic.Len())
+	args = append(args, valuesToInterfaces(fixed)...)
+	for i := range variadic.Len() {
+		args = append(args, variadic.Index(i).Interface())
+	}
+	return args
+}
+
+// matchArgs 参数是否全部匹配。matchers 是 nil 时匹配任意参数。
+func matchArgs(matchers []Matcher, args []any) bool {
+	if matchers == nil {
+		return true
+	}
+	if len(matchers) != len(args) {
+		return false
+	}
+	for i, m := range matchers {
+		if !m.Match(args[i]) {
+			return false
+		}
+	}
+	return true
+}
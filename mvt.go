@@ -14,6 +14,7 @@ package modify_variables_temporarily
 
 import (
 	"reflect"
+	"strings"
 	"unicode"
 )
 
@@ -35,7 +36,8 @@ func Var(target, substitute any) Resetter {
 	oldElem := elemValue.Interface()
 	newElemValue := convertSubstituteToTypeValue(substitute, elemValue.Type())
 	elemValue.Set(newElemValue)
-	return newResetter(generateSetOldFunc(elemValue, oldElem))
+	return newResetter(describe(elemValue.Type(), "", oldElem, newElemValue.Interface()),
+		generateSetOldFunc(elemValue, oldElem))
 }
 
 // FieldByName 替换结构体字段的值。
@@ -64,7 +66,8 @@ func FieldByName(target any, name string, substitute any) Resetter {
 	oldField := fieldValue.Interface()
 	newFieldValue := convertSubstituteToTypeValue(substitute, fieldValue.Type())
 	fieldValue.Set(newFieldValue)
-	return newResetter(generateSetOldFunc(fieldValue, oldField))
+	return newResetter(describe(fieldValue.Type(), typePath(structValue.Type(), fieldValue.Type()), oldField,
+		newFieldValue.Interface()), generateSetOldFunc(fieldValue, oldField))
 }
 
 // Field 替换结构体字段的值。
@@ -90,7 +93,8 @@ func Field(target any, index int, substitute any) Resetter {
 	oldField := fieldValue.Interface()
 	newFieldValue := convertSubstituteToTypeValue(substitute, fieldValue.Type())
 	fieldValue.Set(newFieldValue)
-	return newResetter(generateSetOldFunc(fieldValue, oldField))
+	return newResetter(describe(fieldValue.Type(), typePath(structValue.Type(), fieldValue.Type()), oldField,
+		newFieldValue.Interface()), generateSetOldFunc(fieldValue, oldField))
 }
 
 // Elem 替换切片的元素值。
@@ -112,7 +116,8 @@ func Elem(target any, index int, substitute any) Resetter {
 	oldElem := elemValue.Interface()
 	newElemValue := convertSubstituteToTypeValue(substitute, elemValue.Type())
 	elemValue.Set(newElemValue)
-	return newResetter(generateSetOldFunc(elemValue, oldElem))
+	return newResetter(describe(elemValue.Type(), typePath(sliceValue.Type(), elemValue.Type()), oldElem,
+		newElemValue.Interface()), generateSetOldFunc(elemValue, oldElem))
 }
 
 // Map 替换映射中的某个键的值。
@@ -133,13 +138,39 @@ func Map(target, key any, substitute any) Resetter {
 	keyValue, valValue := getMapValueByKey(mapValue, key)
 	newValValue := convertSubstituteToTypeValue(substitute, mapValue.Type().Elem())
 	mapValue.SetMapIndex(keyValue, newValValue)
-	return newResetter(func() { mapValue.SetMapIndex(keyValue, valValue) })
+	return newResetter(describe(newValValue.Type(), typePath(mapValue.Type(), newValValue.Type()),
+		interfaceOrAbsent(valValue), newValValue.Interface()),
+		func() { restoreMapIndex(mapValue, keyValue, valValue) })
 }
 
-// FuncOuts 替换函数变量以固定次数返回值代替。
+// MapDelete 临时删除映射中的某个键。
+// target 被删除键的映射变量，不能是 nil。
+// key 将被删除的键，须存在于映射中。
+func MapDelete(target, key any) Resetter {
+	if target == nil {
+		panic(ErrTargetCannotBeNil)
+	}
+	mapValue := reflect.ValueOf(target)
+	if mapValue.Kind() != reflect.Map {
+		panic(ErrTargetIsNotMap)
+	}
+	if mapValue.IsZero() {
+		panic(ErrTargetCannotBeNilType)
+	}
+	keyValue, valValue := getMapValueByKey(mapValue, key)
+	if !valValue.IsValid() {
+		panic(newMapKeyNotFoundError(keyValue, mapValue))
+	}
+	mapValue.SetMapIndex(keyValue, reflect.Value{})
+	m := describe(valValue.Type(), typePath(mapValue.Type(), valValue.Type()), valValue.Interface(), label("<deleted>"))
+	return newResetter(m, func() { mapValue.SetMapIndex(keyValue, valValue) })
+}
+
+// FuncOuts 替换函数变量以固定次数返回值代替。返回的 FuncResetter 记录了函数的调用。
 // target 要被替换返回值的函数指针变量，不能是 nil。
 // outs 替换成的输出值，函数返回值将会复制 outs 中的值返回，
-func FuncOuts(target any, outs []OutValue) Resetter {
+// opts 替换选项，如返回值用尽时的行为。
+func FuncOuts(target any, outs []OutValue, opts ...FuncOption) FuncResetter {
 	if target == nil {
 		panic(ErrTargetCannotBeNil)
 	}
@@ -152,13 +183,48 @@ func FuncOuts(target any, outs []OutValue) Resetter {
 		panic(ErrTargetIsNotFunc)
 	}
 	fn := funcValue.Interface()
-	funcValue.Set(makeFunc(funcValue, outs))
-	return newResetter(generateSetOldFunc(funcValue, fn))
+	newFuncValue, stub := makeFunc(funcValue, outs, opts)
+	funcValue.Set(newFuncValue)
+	return &funcResetter{newResetter(describeFuncOuts(funcValue.Type(), "", fn, outs), generateSetOldFunc(funcValue, fn)), stub}
+}
+
+// FuncErr 替换函数变量，以 err 作为最后一个返回值返回 times 次，其它返回值是零值。
+// target 要被替换返回值的函数指针变量，不能是 nil，函数的最后一个返回值须是 error 类型。
+// err 返回的错误。
+// times 返回次数，0 和 1 表示仅返回一次，用尽后调用原函数。
+func FuncErr(target any, err error, times int) FuncResetter {
+	return funcErr(target, err, times, nil)
+}
+
+func funcErr(target any, err error, times int, opts []FuncOption) FuncResetter {
+	funcType := getFuncTypeOfTarget(target)
+	numOut := funcType.NumOut()
+	if numOut <= 0 || funcType.Out(numOut-1) != reflect.TypeFor[error]() {
+		panic(newFuncHasNoErrorResultError(funcType.String()))
+	}
+	values := make([]any, numOut)
+	values[numOut-1] = err
+	return FuncOuts(target, []OutValue{{Values: values, Times: times}}, opts...)
+}
+
+// FuncReturns 替换函数变量，总是返回 values。
+// target 要被替换返回值的函数指针变量，不能是 nil。
+// values 返回值，数量须与函数返回值一致。
+func FuncReturns(target any, values ...any) FuncResetter {
+	return funcReturns(target, values, nil)
+}
+
+func funcReturns(target any, values []any, opts []FuncOption) FuncResetter {
+	funcType := getFuncTypeOfTarget(target)
+	if len(values) != funcType.NumOut() {
+		panic(newFuncOutsCountMismatchError(funcType.String(), len(values)))
+	}
+	return FuncOuts(target, []OutValue{{Values: values}}, append([]FuncOption{RepeatLast()}, opts...)...)
 }
 
 // Chain 根据索引替换深层值。
-// target 必须是可寻址的变量，不能是 nil。
-func Chain(target any) Chainer {
+// target 必须是可寻址的变量，不能是 nil。opts 链式修改的选项，如 NoAutoAlloc。
+func Chain(target any, opts ...ChainOption) Chainer {
 	if target == nil {
 		panic(ErrTargetCannotBeNil)
 	}
@@ -173,7 +239,40 @@ func Chain(target any) Chainer {
 			panic(ErrTargetCannotBeSet)
 		}
 	}
-	return &chainSetter{value: &value}
+	return &chainSetter{value: &value, options: newChainOptions(opts)}
+}
+
+// typePath 将经过的类型连接成路径。
+func typePath(types ...reflect.Type) string {
+	names := make([]string, len(types))
+	for i, v := range types {
+		names[i] = v.String()
+	}
+	return strings.Join(names, " -> ")
+}
+
+// interfaceOrAbsent 获取映射键值，键不存在时返回表示不存在的描述。
+func interfaceOrAbsent(value reflect.Value) any {
+	if !value.IsValid() {
+		return label("<absent>")
+	}
+	return value.Interface()
+}
+
+// getFuncTypeOfTarget 获取函数指针变量的函数类型。
+func getFuncTypeOfTarget(target any) reflect.Type {
+	if target == nil {
+		panic(ErrTargetCannotBeNil)
+	}
+	ptrType := reflect.TypeOf(target)
+	if ptrType.Kind() != reflect.Pointer {
+		panic(ErrTargetIsNotPointer)
+	}
+	funcType := ptrType.Elem()
+	if funcType.Kind() != reflect.Func {
+		panic(ErrTargetIsNotFunc)
+	}
+	return funcType
 }
 
 func convertSubstituteToTypeValue(substitute any, typ reflect.Type) reflect.Value {
@@ -212,7 +311,7 @@ func generateSetOldFunc(value reflect.Value, old any) func() {
 func getStructFieldByName(structValue reflect.Value, name string) reflect.Value {
 	fieldValue := structValue.FieldByName(name)
 	if !fieldValue.IsValid() {
-		panic(newStructFieldNotFoundByNameError(structValue.Type().String(), name))
+		panic(newStructFieldNotFoundByNameError(structValue.Type(), name))
 	}
 	fieldType := fieldValue.Type()
 	if !unicode.IsUpper(rune(name[0])) {
@@ -224,7 +323,7 @@ func getStructFieldByName(structValue reflect.Value, name string) reflect.Value
 func getStructField(structValue reflect.Value, index int) reflect.Value {
 	numField := structValue.NumField()
 	if index >= numField || index < -numField {
-		panic(newStructFieldNotFoundError(structValue.Type().String(), index))
+		panic(newStructFieldNotFoundError(structValue.Type(), index))
 	}
 	revisedIndex := index
 	if index < 0 {
@@ -242,7 +341,7 @@ func getStructField(structValue reflect.Value, index int) reflect.Value {
 func getSequenceIndex(seqValue reflect.Value, index int) reflect.Value {
 	length := seqValue.Len()
 	if index >= length || index < -length {
-		panic(newIndexOutOfBoundError(index, seqValue.Type().String(), length))
+		panic(newIndexOutOfBoundError(index, seqValue.Type(), length))
 	}
 	revisedIndex := index
 	if index < 0 {
@@ -271,3 +370,12 @@ func getMapValueByKey(mapValue reflect.Value, key any) (keyValue reflect.Value,
 	}
 	return
 }
+
+// restoreMapIndex 恢复映射中的键值。oldValue 无效时表示键原本不存在，将删除该键。
+func restoreMapIndex(mapValue, keyValue, oldValue reflect.Value) {
+	if oldValue.IsValid() {
+		mapValue.SetMapIndex(keyValue, oldValue)
+	} else {
+		mapValue.SetMapIndex(keyValue, reflect.Value{})
+	}
+}
@@ -13,12 +13,18 @@
 package modify_variables_temporarily_test
 
 import (
+	"debug/elf"
 	"errors"
 	"fmt"
 	"math/rand"
+	"os"
 	"reflect"
+	"runtime"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	mvt "gitee.com/ivfzhou/modify-variables-temporarily/v3"
 )
@@ -38,6 +44,102 @@ type testStruct struct {
 	unexportedField4 map[any]any
 }
 
+type testLogger struct{ last string }
+
+func (l *testLogger) logf(format string, args ...any) error {
+	l.last = fmt.Sprintf(format, args...)
+	return nil
+}
+
+type testSnapshotNode struct {
+	value   int
+	next    *testSnapshotNode
+	self    *testSnapshotNode
+	m       map[string]*testSnapshotNode
+	s       []int
+	a       [2]*testSnapshotNode
+	i       any
+	fn      func() int
+	ch      chan int
+	Version int
+}
+
+type testSymbolStruct struct {
+	value int
+	name  string
+}
+
+var testSymbol = testSymbolStruct{value: 1, name: "symbol"}
+
+type testCounter struct {
+	n       int
+	history []int
+}
+
+//go:noinline
+func (c *testCounter) add(n int) int {
+	c.n += n
+	c.history = append(c.history, n)
+	return c.n
+}
+
+//go:noinline
+func testFormat(v int) string { return fmt.Sprintf("value: %d", v) }
+
+func testTiny() {}
+
+type testPlugin struct {
+	enabled bool
+	handler func(int) int
+}
+
+type testPlugins struct {
+	list []testPlugin
+	m    map[string]*testPlugin
+	arr  [3]int
+}
+
+type panicResetter struct{ v any }
+
+func (r panicResetter) Reset() { panic(r.v) }
+
+// fakeTB 记录 Fatalf 和 Cleanup 的 testing.TB。
+type fakeTB struct {
+	testing.TB
+	fatal    string
+	errors   string
+	cleanups []func()
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Fatalf(format string, args ...any) {
+	tb.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func (tb *fakeTB) Errorf(format string, args ...any) { tb.errors += fmt.Sprintf(format, args...) }
+
+func (tb *fakeTB) Cleanup(fn func()) { tb.cleanups = append(tb.cleanups, fn) }
+
+// cleanup 按相反顺序执行 Cleanup 注册的函数。
+func (tb *fakeTB) cleanup() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+	tb.cleanups = nil
+}
+
+// run 在新协程中运行 fn，以便 Fatalf 可以终止它。
+func (tb *fakeTB) run(fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	<-done
+}
+
 func TestVar(t *testing.T) {
 	t.Run("Target 是 nil", func(t *testing.T) {
 		for range 100 {
@@ -838,6 +940,47 @@ func TestMap(t *testing.T) {
 	})
 }
 
+func TestMapDelete(t *testing.T) {
+	t.Run("Target 不是映射", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrTargetIsNotMap) {
+				t.Error("no ErrTargetIsNotMap panic occurred", recovered)
+			}
+		}()
+		mvt.MapDelete([]int{}, 1)
+	})
+
+	t.Run("键不存在", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrMapKeyNotFound) {
+				t.Error("no ErrMapKeyNotFound panic occurred", recovered)
+			}
+		}()
+		mvt.MapDelete(map[int]int{1: 1}, 2)
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		for range 100 {
+			key := rand.Intn(1000)
+			var originalValue any
+			if rand.Intn(2) > 0 {
+				originalValue = rand.Intn(1000)
+			}
+			target := map[any]any{key: originalValue}
+			reset := mvt.MapDelete(target, key)
+			if _, ok := target[key]; ok {
+				t.Error("target does not meet expectation", target)
+			}
+			reset.Reset()
+			if v, ok := target[key]; !ok || v != originalValue {
+				t.Error("target does not meet expectation", target, originalValue)
+			}
+		}
+	})
+}
+
 func TestFuncOuts(t *testing.T) {
 	t.Run("Target 是 nil", func(t *testing.T) {
 		for range 100 {
@@ -960,45 +1103,130 @@ func TestFuncOuts(t *testing.T) {
 	t.Run("outs 有多余", func(t *testing.T) {
 		fn := func() (int, int) { return 0, 0 }
 		for range 100 {
-			outValues := []mvt.OutValue{
-				{
-					Values: []any{rand.Intn(1000), rand.Intn(1000), rand.Intn(1000)},
-				},
-			}
-			reset := mvt.FuncOuts(&fn, outValues)
-			v, v2 := fn()
-			if v != outValues[0].Values[0] || v2 != outValues[0].Values[1] {
-				t.Error("values func returned does not meet expectation", v, v2, outValues[0].Values[0], outValues[0].Values[1])
-			}
-			reset.Reset()
-			v, v2 = fn()
-			if v != 0 || v2 != 0 {
-				t.Error("values func returned does not meet expectation", v, v2)
-			}
+			func() {
+				defer func() {
+					var mismatch *mvt.OutValueMismatchError
+					recovered, _ := recover().(error)
+					if !errors.As(recovered, &mismatch) || !errors.Is(recovered, mvt.ErrFuncOutsMismatch) {
+						t.Fatal("no OutValueMismatchError panic occurred", recovered)
+					}
+					if mismatch.OutIndex != 1 || mismatch.ResultIndex != 2 || mismatch.Expected != nil ||
+						mismatch.Given != reflect.TypeOf(0) {
+						t.Error("mismatch error does not meet expectation", mismatch)
+					}
+					if v, v2 := fn(); v != 0 || v2 != 0 {
+						t.Error("values func returned does not meet expectation", v, v2)
+					}
+				}()
+				mvt.FuncOuts(&fn, []mvt.OutValue{
+					{Values: []any{rand.Intn(1000), rand.Intn(1000)}},
+					{Values: []any{rand.Intn(1000), rand.Intn(1000), rand.Intn(1000)}},
+				})
+			}()
 		}
 	})
 
 	t.Run("outs 少了", func(t *testing.T) {
 		fn := func() (int, int) { return 0, 0 }
 		for range 100 {
-			outValues := []mvt.OutValue{
-				{
-					Values: []any{rand.Intn(1000)},
-				},
+			func() {
+				defer func() {
+					var mismatch *mvt.OutValueMismatchError
+					recovered, _ := recover().(error)
+					if !errors.As(recovered, &mismatch) || errors.Is(recovered, mvt.ErrIncompatibleTypeAssignment) {
+						t.Fatal("no OutValueMismatchError panic occurred", recovered)
+					}
+					if mismatch.OutIndex != 0 || mismatch.ResultIndex != 1 || mismatch.Expected != reflect.TypeOf(0) ||
+						mismatch.Given != nil {
+						t.Error("mismatch error does not meet expectation", mismatch)
+					}
+				}()
+				mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{rand.Intn(1000)}}})
+			}()
+		}
+	})
+
+	t.Run("outs 类型不同", func(t *testing.T) {
+		fn := func() (int, error) { return 1, nil }
+		for range 100 {
+			func() {
+				defer func() {
+					var mismatch *mvt.OutValueMismatchError
+					recovered, _ := recover().(error)
+					if !errors.As(recovered, &mismatch) || !errors.Is(recovered, mvt.ErrIncompatibleTypeAssignment) {
+						t.Fatal("no OutValueMismatchError panic occurred", recovered)
+					}
+					if mismatch.ResultIndex != 1 || mismatch.Expected != reflect.TypeOf((*error)(nil)).Elem() ||
+						mismatch.Given != reflect.TypeOf("") {
+						t.Error("mismatch error does not meet expectation", mismatch)
+					}
+				}()
+				mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{rand.Intn(1000), "error"}}})
+			}()
+		}
+	})
+
+	t.Run("outs 转换丢失信息", func(t *testing.T) {
+		fnString := func() string { return "" }
+		fnInt8 := func() int8 { return 0 }
+		fnFloat32 := func() float32 { return 0 }
+		cases := []struct {
+			target any
+			value  any
+		}{
+			{&fnString, 65},
+			{&fnInt8, 300},
+			{&fnInt8, 1.5},
+			{&fnFloat32, 1e300},
+		}
+		for i, v := range cases {
+			_, err := mvt.TryFuncOuts(v.target, []mvt.OutValue{{Values: []any{v.value}}})
+			var mismatch *mvt.OutValueMismatchError
+			if !errors.As(err, &mismatch) || mismatch.Given != reflect.TypeOf(v.value) {
+				t.Error("no OutValueMismatchError returned", i, err)
 			}
-			reset := mvt.FuncOuts(&fn, outValues)
-			v, v2 := fn()
-			if v != outValues[0].Values[0] || v2 != 0 {
-				t.Error("values func returned does not meet expectation", v, v2, outValues[0].Values[0], outValues[0].Values[1])
+		}
+		if fnString() != "" || fnInt8() != 0 || fnFloat32() != 0 {
+			t.Error("func was modified")
+		}
+
+		reset := mvt.FuncOuts(&fnInt8, []mvt.OutValue{{Values: []any{-128}}})
+		reset2 := mvt.FuncOuts(&fnFloat32, []mvt.OutValue{{Values: []any{0.5}}})
+		if v, v2 := fnInt8(), fnFloat32(); v != -128 || v2 != 0.5 {
+			t.Error("values func returned does not meet expectation", v, v2)
+		}
+		reset2.Reset()
+		reset.Reset()
+	})
+
+	t.Run("outs 为空", func(t *testing.T) {
+		fn := func() (int, error) { return 1, errors.New("error") }
+		for range 100 {
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Times: 2}})
+			for range 2 {
+				if v, err := fn(); v != 0 || err != nil {
+					t.Error("values func returned does not meet expectation", v, err)
+				}
 			}
 			reset.Reset()
-			v, v2 = fn()
-			if v != 0 || v2 != 0 {
-				t.Error("values func returned does not meet expectation", v, v2)
-			}
 		}
 	})
 
+	t.Run("Do 返回值不符", func(t *testing.T) {
+		fn := func(int) (int, int) { return 0, 0 }
+		reset := mvt.FuncOuts(&fn, []mvt.OutValue{{}, {Do: func(args []any) []any { return []any{args[0]} }}})
+		defer reset.Reset()
+		fn(1)
+		defer func() {
+			var mismatch *mvt.OutValueMismatchError
+			recovered, _ := recover().(error)
+			if !errors.As(recovered, &mismatch) || mismatch.OutIndex != 1 || mismatch.ResultIndex != 1 {
+				t.Error("no OutValueMismatchError panic occurred", recovered)
+			}
+		}()
+		fn(1)
+	})
+
 	t.Run("outs 中有 nil", func(t *testing.T) {
 		fn := func() (int, int) { return 1, 1 }
 		for range 100 {
@@ -1117,589 +1345,2588 @@ func TestFuncOuts(t *testing.T) {
 	})
 }
 
-func TestChain(t *testing.T) {
-	t.Run("测试指针 1", func(t *testing.T) {
+func TestFuncOutsRecord(t *testing.T) {
+	t.Run("记录调用", func(t *testing.T) {
+		fn := func(a int, b string) (int, string) { return a, b }
 		for range 100 {
-			var target any
 			value := rand.Intn(1000)
-			reset := mvt.Chain(&target).Elem().Set(value)
-			if target != value {
-				t.Log("target value does not meet expectation", target, value)
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{value, "ok"}}})
+			if reset.CallCount() != 0 || reset.LastArgs() != nil {
+				t.Error("record does not meet expectation", reset.Calls())
+			}
+			fn(1, "a")
+			fn(2, "b")
+			calls := reset.Calls()
+			if reset.CallCount() != 2 || !reflect.DeepEqual(calls, [][]any{{1, "a"}, {2, "b"}}) {
+				t.Error("record does not meet expectation", calls)
+			}
+			if !reflect.DeepEqual(reset.LastArgs(), []any{2, "b"}) {
+				t.Error("record does not meet expectation", reset.LastArgs())
+			}
+			if outs := reset.Outs(); !reflect.DeepEqual(outs, [][]any{{value, "ok"}, {2, "b"}}) {
+				t.Error("record does not meet expectation", outs)
 			}
 			reset.Reset()
-			if target != nil {
-				t.Error("target value does not meet expectation", target)
+			fn(3, "c")
+			if reset.CallCount() != 2 {
+				t.Error("record does not meet expectation", reset.Calls())
 			}
 		}
 	})
 
-	t.Run("测试指针 2", func(t *testing.T) {
+	t.Run("并发记录", func(t *testing.T) {
+		type st struct {
+			fn func(int) int
+		}
 		for range 100 {
-			var target *any
-			value := rand.Intn(1000)
-			reset := mvt.Chain(&target).Elem().Elem().Set(value)
-			if *target != value {
-				t.Log("target value does not meet expectation", target, value)
+			data := &st{}
+			reset := mvt.Chain(&data).Elem().Elem().FieldByName("fn").SetFuncOuts([]mvt.OutValue{{Values: []any{1}, Times: 100}})
+			wg := sync.WaitGroup{}
+			for i := range 100 {
+				wg.Go(func() { data.fn(i) })
 			}
-			reset.Reset()
-			if target != nil {
-				t.Error("target value does not meet expectation", target)
+			wg.Wait()
+			sum := 0
+			for _, v := range reset.Calls() {
+				sum += v[0].(int)
+			}
+			if reset.CallCount() != 100 || sum != 4950 {
+				t.Error("record does not meet expectation", reset.CallCount(), sum)
 			}
+			reset.Reset()
 		}
 	})
+}
 
-	t.Run("测试指针 3", func(t *testing.T) {
+func TestFuncOutsArgs(t *testing.T) {
+	t.Run("参数数量不一致", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrArgsCountMismatch) {
+				t.Error("no ErrArgsCountMismatch panic occurred", recovered)
+			}
+		}()
+		fn := func(int) int { return 0 }
+		mvt.FuncOuts(&fn, []mvt.OutValue{{Args: []any{1, 2}, Values: []any{1}}})
+	})
+
+	t.Run("参数类型不兼容", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrIncompatibleTypeAssignment) {
+				t.Error("no ErrIncompatibleTypeAssignment panic occurred", recovered)
+			}
+		}()
+		fn := func(int) int { return 0 }
+		mvt.FuncOuts(&fn, []mvt.OutValue{{Args: []any{""}, Values: []any{1}}})
+	})
+
+	t.Run("按参数匹配", func(t *testing.T) {
+		fn := func(id int64, name string) string { return "original" }
 		for range 100 {
-			var target *map[any]any
-			key := rand.Intn(1000)
-			value := map[any]any{key: rand.Intn(1000)}
-			reset := mvt.Chain(&target).Elem().Elem().Set(value)
-			if (*target)[key] != value[key] {
-				t.Log("target value does not meet expectation", target, value)
+			id := rand.Intn(1000)
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{
+				{Args: []any{id, mvt.Any()}, Values: []any{"id"}, Times: 2},
+				{Args: []any{mvt.Any(), func(v any) bool { return v.(string) == "name" }}, Values: []any{"name"}},
+				{Args: []any{mvt.Eq(int64(id + 1)), mvt.Eq("")}, Values: []any{"eq"}},
+				{Values: []any{"any"}},
+			})
+			results := []string{
+				fn(int64(id+2), "x"),
+				fn(int64(id), "name"),
+				fn(int64(id+2), "name"),
+				fn(int64(id), "x"),
+				fn(int64(id), "x"),
+				fn(int64(id+1), ""),
+				fn(int64(id+1), ""),
+			}
+			expected := []string{"any", "id", "name", "id", "original", "eq", "original"}
+			if !reflect.DeepEqual(results, expected) {
+				t.Error("values func returned does not meet expectation", results, expected)
 			}
 			reset.Reset()
-			if target != nil {
-				t.Error("target value does not meet expectation", target)
-			}
 		}
 	})
+}
 
-	t.Run("测试指针 4", func(t *testing.T) {
+func TestFuncOutsDo(t *testing.T) {
+	t.Run("根据参数计算返回值", func(t *testing.T) {
+		fn := func(id int) (int, error) { return 0, nil }
 		for range 100 {
-			var target *testInterface
-			value := testImpl(rand.Intn(1000))
-			reset := mvt.Chain(&target).Elem().Elem().Set(value)
-			if *target != value {
-				t.Log("target value does not meet expectation", target, value)
+			times := rand.Intn(5) + 1
+			failed := errors.New("failed")
+			count := 0
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{
+				{
+					Do: func(args []any) []any {
+						count++
+						if count%3 == 0 {
+							return []any{nil, failed}
+						}
+						return []any{args[0].(int) * 2, nil}
+					},
+					Times: times,
+				},
+			})
+			for i := range times + 1 {
+				v, err := fn(i)
+				switch {
+				case i >= times:
+					if v != 0 || err != nil {
+						t.Error("values func returned does not meet expectation", v, err)
+					}
+				case (i+1)%3 == 0:
+					if v != 0 || err != failed {
+						t.Error("values func returned does not meet expectation", v, err)
+					}
+				default:
+					if v != i*2 || err != nil {
+						t.Error("values func returned does not meet expectation", v, err)
+					}
+				}
 			}
 			reset.Reset()
-			if target != nil {
-				t.Error("target value does not meet expectation", target)
-			}
 		}
 	})
+}
 
-	t.Run("测试指针 5", func(t *testing.T) {
+func TestFuncOutsExhausted(t *testing.T) {
+	t.Run("Strict", func(t *testing.T) {
+		fn := func(int, string) int { return 0 }
 		for range 100 {
-			var impl testInterface = testImpl(rand.Intn(1000))
-			var target *testInterface = &impl
-			value := testImpl(rand.Intn(1000))
-			reset := mvt.Chain(&target).Elem().Elem().Elem().Set(value)
-			if *target != value {
-				t.Log("target value does not meet expectation", target, value)
+			value := rand.Intn(1000)
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{value}}}, mvt.Strict())
+			if v := fn(1, "a"); v != value {
+				t.Error("values func returned does not meet expectation", v, value)
 			}
+			func() {
+				defer func() {
+					recovered, _ := recover().(error)
+					if !errors.Is(recovered, mvt.ErrUnexpectedCall) || !strings.Contains(recovered.Error(), `2, "b"`) {
+						t.Error("no ErrUnexpectedCall panic occurred", recovered)
+					}
+				}()
+				fn(2, "b")
+			}()
 			reset.Reset()
-			if *target != impl {
-				t.Error("target value does not meet expectation", target)
-			}
 		}
 	})
 
-	t.Run("测试结构体 1", func(t *testing.T) {
+	t.Run("Strict 绑定测试", func(t *testing.T) {
+		fn := func() (int, error) { return 1, nil }
+		tb := &fakeTB{TB: t}
+		mvt.T(tb).FuncOuts(&fn, nil, mvt.Strict())
+		if v, err := fn(); v != 0 || err != nil {
+			t.Error("values func returned does not meet expectation", v, err)
+		}
+		if !strings.Contains(tb.errors, mvt.ErrUnexpectedCall.Error()) {
+			t.Error("no ErrUnexpectedCall reported", tb.errors)
+		}
+		tb.cleanup()
+
+		var data *struct{ fn func() int }
+		tb = &fakeTB{TB: t}
+		mvt.T(tb).Chain(&data).Elem().Elem().Field(0).SetFuncOuts(nil, mvt.Strict())
+		if v := data.fn(); v != 0 {
+			t.Error("values func returned does not meet expectation", v)
+		}
+		if !strings.Contains(tb.errors, mvt.ErrUnexpectedCall.Error()) {
+			t.Error("no ErrUnexpectedCall reported", tb.errors)
+		}
+		tb.cleanup()
+		if data != nil {
+			t.Error("target value does not meet expectation", data)
+		}
+	})
+
+	t.Run("RepeatLast", func(t *testing.T) {
+		fn := func(int) int { return -1 }
 		for range 100 {
-			var target testStruct
-			chainer := mvt.Chain(&target).Elem()
-			if rand.Intn(2) > 0 {
-				chainer = chainer.Field(0)
-			} else {
-				chainer = chainer.FieldByName("unexportedField")
-			}
-			value := testImpl(rand.Intn(1000))
-			reset := chainer.Set(value)
-			if target.unexportedField != value {
-				t.Error("target value does not meet expectation", target, value)
+			value := rand.Intn(1000)
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{value}}, {Values: []any{value + 1}, Args: []any{1}}}, mvt.RepeatLast())
+			results := []int{fn(0), fn(1), fn(0), fn(1), fn(2)}
+			if !reflect.DeepEqual(results, []int{value, value + 1, value + 1, value + 1, value + 1}) {
+				t.Error("values func returned does not meet expectation", results)
 			}
 			reset.Reset()
-			if target.unexportedField != nil {
-				t.Error("target value does not meet expectation", target.unexportedField)
+
+			reset = mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{value}, Args: []any{1}}}, mvt.RepeatLast())
+			if v := fn(0); v != -1 {
+				t.Error("values func returned does not meet expectation", v)
 			}
+			reset.Reset()
 		}
 	})
 
-	t.Run("测试结构体 2", func(t *testing.T) {
+	t.Run("Cycle", func(t *testing.T) {
+		fn := func(int) int { return -1 }
 		for range 100 {
-			var target testStruct
-			impl := testImpl(rand.Intn(1000))
-			target.unexportedField = impl
-			chainer := mvt.Chain(&target).Elem()
-			if rand.Intn(2) > 0 {
-				chainer = chainer.Field(0)
-			} else {
-				chainer = chainer.FieldByName("unexportedField")
-			}
-			value := testImpl(rand.Intn(1000))
-			reset := chainer.Elem().Set(value)
-			if target.unexportedField != value {
-				t.Error("target value does not meet expectation", target, value)
+			value := rand.Intn(1000)
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{value}, Times: 2}, {Values: []any{value + 1}, Args: []any{1}}}, mvt.Cycle())
+			results := []int{fn(0), fn(1), fn(1), fn(0), fn(0), fn(0), fn(1), fn(2)}
+			if !reflect.DeepEqual(results, []int{value, value, value + 1, value, value, value, value, value}) {
+				t.Error("values func returned does not meet expectation", results)
 			}
 			reset.Reset()
-			if target.unexportedField != impl {
-				t.Error("target value does not meet expectation", target.unexportedField)
-			}
 		}
 	})
+}
 
-	t.Run("测试结构体 3", func(t *testing.T) {
+func TestFuncOutsExpect(t *testing.T) {
+	t.Run("满足期望", func(t *testing.T) {
+		fn := func(int) int { return 0 }
 		for range 100 {
-			var target testStruct
-			chainer := mvt.Chain(&target).Elem()
-			if rand.Intn(2) > 0 {
-				chainer = chainer.Field(2)
-			} else {
-				chainer = chainer.FieldByName("unexportedField3")
+			times := rand.Intn(5) + 1
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{1}, Times: times}},
+				mvt.ExpectCalls(times+1), mvt.ExpectConsumed(), mvt.ExpectArgs([]any{0}, []any{mvt.Any()}))
+			for i := range times + 1 {
+				fn(i)
 			}
-			value := testImpl(rand.Intn(1000))
-			reset := chainer.Elem().Set(value)
-			if *target.unexportedField3 != value {
-				t.Error("target value does not meet expectation", target, value)
+			if err := reset.Check(); err != nil {
+				t.Error("error occurred", err)
 			}
+			reset.Verify(t)
 			reset.Reset()
-			if target.unexportedField3 != nil {
-				t.Error("target value does not meet expectation", target.unexportedField)
-			}
 		}
 	})
 
-	t.Run("测试结构体 4", func(t *testing.T) {
-		for range 100 {
-			var target testStruct
-			impl := testImpl(rand.Intn(1000))
-			target.unexportedField3 = &impl
-			chainer := mvt.Chain(&target).Elem()
-			if rand.Intn(2) > 0 {
-				chainer = chainer.Field(2)
-			} else {
-				chainer = chainer.FieldByName("unexportedField3")
+	t.Run("不满足期望", func(t *testing.T) {
+		fn := func(int) int { return 0 }
+		cases := []struct {
+			opts  []mvt.FuncOption
+			calls int
+			msg   string
+		}{
+			{[]mvt.FuncOption{mvt.ExpectCalls(2)}, 1, "expect 2 calls, got 1"},
+			{[]mvt.FuncOption{mvt.ExpectMinCalls(2)}, 1, "expect at least 2 calls, got 1"},
+			{[]mvt.FuncOption{mvt.ExpectMaxCalls(2)}, 3, "expect at most 2 calls, got 3"},
+			{[]mvt.FuncOption{mvt.ExpectConsumed()}, 1, "outs[0] used 1 of 2 times"},
+			{[]mvt.FuncOption{mvt.ExpectArgs([]any{0}, []any{2})}, 2, "call 1 args []interface {}{1} not match"},
+			{[]mvt.FuncOption{mvt.ExpectArgs(nil, nil)}, 1, "call 1 was not made"},
+		}
+		for _, v := range cases {
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{1}, Times: 2}}, v.opts...)
+			for i := range v.calls {
+				fn(i)
 			}
-			value := testImpl(rand.Intn(1000))
-			reset := chainer.Elem().Set(value)
-			if *target.unexportedField3 != value {
-				t.Error("target value does not meet expectation", target, value)
+			err := reset.Check()
+			if !errors.Is(err, mvt.ErrExpectationNotMet) || !strings.Contains(err.Error(), v.msg) {
+				t.Error("error does not meet expectation", err, v.msg)
 			}
-			reset.Reset()
-			if *target.unexportedField3 != impl {
-				t.Error("target value does not meet expectation", target.unexportedField, impl)
+			tb := &fakeTB{TB: t}
+			reset.Verify(tb)
+			if !strings.Contains(tb.errors, v.msg) {
+				t.Error("error does not meet expectation", tb.errors, v.msg)
 			}
+			reset.Reset()
 		}
 	})
 
-	t.Run("测试结构体 5", func(t *testing.T) {
+	t.Run("绑定测试时自动检查", func(t *testing.T) {
+		fn := func(int) int { return 0 }
+		tb := &fakeTB{TB: t}
+		mvt.T(tb).FuncOuts(&fn, nil, mvt.ExpectCalls(1))
+		tb.cleanup()
+		if !strings.Contains(tb.errors, "expect 1 calls, got 0") {
+			t.Error("error does not meet expectation", tb.errors)
+		}
+
+		data := &struct{ fn func() }{}
+		tb = &fakeTB{TB: t}
+		mvt.T(tb).Chain(&data).Elem().Elem().Field(0).SetFuncOuts(nil, mvt.ExpectMinCalls(1))
+		tb.cleanup()
+		if !strings.Contains(tb.errors, "expect at least 1 calls, got 0") {
+			t.Error("error does not meet expectation", tb.errors)
+		}
+	})
+}
+
+func TestFuncOutsInject(t *testing.T) {
+	t.Run("Panic", func(t *testing.T) {
+		fn := func() int { return 0 }
 		for range 100 {
-			var target testStruct
-			chainer := mvt.Chain(&target).Elem()
-			if rand.Intn(2) > 0 {
-				chainer = chainer.Field(3)
-			} else {
-				chainer = chainer.FieldByName("unexportedField4")
+			value := rand.Intn(1000)
+			reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Panic: value}})
+			func() {
+				defer func() {
+					if recovered := recover(); recovered != value {
+						t.Error("panic does not meet expectation", recovered, value)
+					}
+				}()
+				fn()
+			}()
+			if v := fn(); v != 0 {
+				t.Error("values func returned does not meet expectation", v)
 			}
-			key := rand.Intn(1000)
-			value := map[any]any{key: testImpl(rand.Intn(1000))}
-			reset := chainer.Set(value)
-			if target.unexportedField4[key] != value[key] {
-				t.Error("target value does not meet expectation", target, value)
+			if outs := reset.Outs(); len(outs) != 2 || outs[0] != nil {
+				t.Error("record does not meet expectation", outs)
 			}
 			reset.Reset()
-			if target.unexportedField4 != nil {
-				t.Error("target value does not meet expectation", target.unexportedField)
-			}
 		}
 	})
 
-	t.Run("测试结构体 6", func(t *testing.T) {
-		for range 100 {
-			var target testStruct
-			key := rand.Intn(1000)
-			impl := testImpl(rand.Intn(1000))
-			target.unexportedField4 = map[any]any{key: impl}
-			chainer := mvt.Chain(&target).Elem()
-			if rand.Intn(2) > 0 {
-				chainer = chainer.Field(3)
-			} else {
-				chainer = chainer.FieldByName("unexportedField4")
+	t.Run("WaitFor 和 Delay", func(t *testing.T) {
+		fn := func() int { return 0 }
+		ch := make(chan struct{})
+		delay := 10 * time.Millisecond
+		reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{1}, WaitFor: ch, Delay: delay}})
+		defer reset.Reset()
+		result := make(chan int, 1)
+		go func() { result <- fn() }()
+		select {
+		case v := <-result:
+			t.Error("func returned before WaitFor fired", v)
+		case <-time.After(delay):
+		}
+		start := time.Now()
+		close(ch)
+		if v := <-result; v != 1 {
+			t.Error("values func returned does not meet expectation", v)
+		}
+		if elapsed := time.Since(start); elapsed < delay {
+			t.Error("func returned before delay", elapsed)
+		}
+	})
+}
+
+func TestFuncErr(t *testing.T) {
+	t.Run("最后一个返回值不是 error", func(t *testing.T) {
+		for _, fn := range []any{new(func()), new(func() (error, int))} {
+			func() {
+				defer func() {
+					recovered, _ := recover().(error)
+					if !errors.Is(recovered, mvt.ErrFuncHasNoErrorResult) {
+						t.Error("no ErrFuncHasNoErrorResult panic occurred", recovered)
+					}
+				}()
+				mvt.FuncErr(fn, nil, 1)
+			}()
+		}
+	})
+
+	t.Run("Target 不是函数", func(t *testing.T) {
+		if _, err := mvt.TryFuncErr(new(int), nil, 1); !errors.Is(err, mvt.ErrTargetIsNotFunc) {
+			t.Error("no ErrTargetIsNotFunc returned", err)
+		}
+		if _, err := mvt.TryFuncErr(func() error { return nil }, nil, 1); !errors.Is(err, mvt.ErrTargetIsNotPointer) {
+			t.Error("no ErrTargetIsNotPointer returned", err)
+		}
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		fn := func() (*testStruct, int, error) { return &testStruct{}, 1, nil }
+		for range 100 {
+			times := rand.Intn(5) + 1
+			failed := errors.New("failed")
+			reset := mvt.FuncErr(&fn, failed, times)
+			for range times {
+				if v, v2, err := fn(); v != nil || v2 != 0 || err != failed {
+					t.Error("values func returned does not meet expectation", v, v2, err)
+				}
 			}
-			value := map[any]any{key: testImpl(rand.Intn(1000))}
-			reset := chainer.Set(value)
-			if target.unexportedField4[key] != value[key] {
-				t.Error("target value does not meet expectation", target, value)
+			if v, v2, err := fn(); v == nil || v2 != 1 || err != nil {
+				t.Error("values func returned does not meet expectation", v, v2, err)
 			}
 			reset.Reset()
-			if target.unexportedField4[key] != impl {
-				t.Error("target value does not meet expectation", target.unexportedField, impl)
-			}
 		}
 	})
+}
 
-	t.Run("测试序列 1", func(t *testing.T) {
-		for range 100 {
-			var target []any = []any{nil}
-			value := rand.Intn(1000)
-			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
-			if target[0] != value {
-				t.Error("target value does not meet expectation", target, value)
-			}
-			reset.Reset()
-			if target[0] != nil {
-				t.Error("target value does not meet expectation", target)
-			}
+func TestFuncReturns(t *testing.T) {
+	t.Run("返回值数量不一致", func(t *testing.T) {
+		fn := func() (int, error) { return 0, nil }
+		if _, err := mvt.TryFuncReturns(&fn, 1); !errors.Is(err, mvt.ErrFuncOutsMismatch) {
+			t.Error("no ErrFuncOutsMismatch returned", err)
+		}
+		if _, err := mvt.TryFuncReturns(&fn, 1, nil, nil); !errors.Is(err, mvt.ErrFuncOutsMismatch) {
+			t.Error("no ErrFuncOutsMismatch returned", err)
 		}
 	})
 
-	t.Run("测试序列 2", func(t *testing.T) {
+	t.Run("返回值类型不兼容", func(t *testing.T) {
+		fn := func() (int, error) { return 0, nil }
+		if _, err := mvt.TryFuncReturns(&fn, "", nil); !errors.Is(err, mvt.ErrIncompatibleTypeAssignment) {
+			t.Error("no ErrIncompatibleTypeAssignment returned", err)
+		}
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		fn := func() (int, error) { return 0, nil }
 		for range 100 {
-			var target [1]any = [1]any{nil}
 			value := rand.Intn(1000)
-			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
-			if target[0] != value {
-				t.Error("target value does not meet expectation", target, value)
+			reset := mvt.FuncReturns(&fn, value, nil)
+			for range rand.Intn(5) + 1 {
+				if v, err := fn(); v != value || err != nil {
+					t.Error("values func returned does not meet expectation", v, err)
+				}
 			}
 			reset.Reset()
-			if target[0] != nil {
-				t.Error("target value does not meet expectation", target)
+			if v, err := fn(); v != 0 || err != nil {
+				t.Error("values func returned does not meet expectation", v, err)
 			}
 		}
 	})
+}
 
-	t.Run("测试序列 3", func(t *testing.T) {
+func TestFuncOutsVariadic(t *testing.T) {
+	t.Run("记录展开的参数", func(t *testing.T) {
+		logf := func(string, ...any) error { return nil }
 		for range 100 {
-			var target []testInterface = []testInterface{testInterface(nil)}
-			impl := testImpl(rand.Intn(1000))
-			target[0] = impl
-			value := testImpl(rand.Intn(1000))
-			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
-			if target[0] != value {
-				t.Error("target value does not meet expectation", target, value)
+			value := rand.Intn(1000)
+			reset := mvt.FuncOuts(&logf, nil)
+			_ = logf("%d %d", value, value+1)
+			_ = logf("none")
+			calls := reset.Calls()
+			if !reflect.DeepEqual(calls, [][]any{{"%d %d", value, value + 1}, {"none"}}) {
+				t.Error("calls does not meet expectation", calls)
 			}
 			reset.Reset()
-			if target[0] != impl {
-				t.Error("target value does not meet expectation", target, impl)
-			}
 		}
 	})
 
-	t.Run("测试序列 4", func(t *testing.T) {
+	t.Run("匹配展开的参数", func(t *testing.T) {
+		logf := func(string, ...any) error { return nil }
 		for range 100 {
-			var target [1]testInterface = [1]testInterface{testInterface(nil)}
-			impl := testImpl(rand.Intn(1000))
-			target[0] = impl
-			value := testImpl(rand.Intn(1000))
-			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
-			if target[0] != value {
-				t.Error("target value does not meet expectation", target, value)
+			value := rand.Intn(1000)
+			err := errors.New("error")
+			reset := mvt.FuncOuts(&logf, []mvt.OutValue{
+				{Args: []any{"%d", value}, Values: []any{err}, Times: 2},
+				{Args: []any{mvt.Any(), mvt.Any(), mvt.Any()}, Values: []any{err}},
+			}, mvt.ExpectArgs([]any{"%d", value}))
+			if v := logf("%d", value); v != err {
+				t.Error("values func returned does not meet expectation", v)
 			}
-			reset.Reset()
-			if target[0] != impl {
-				t.Error("target value does not meet expectation", target, impl)
+			if v := logf("%d", value+1); v != nil {
+				t.Error("values func returned does not meet expectation", v)
+			}
+			if v := logf("%d"); v != nil {
+				t.Error("values func returned does not meet expectation", v)
 			}
+			if v := logf("%d %d", value, value); v != err {
+				t.Error("values func returned does not meet expectation", v)
+			}
+			if err := reset.Check(); err != nil {
+				t.Error("error occurred", err)
+			}
+			reset.Reset()
 		}
 	})
 
-	t.Run("测试序列 5", func(t *testing.T) {
-		for range 100 {
-			var target []map[any]any = []map[any]any{nil}
-			impl := testImpl(rand.Intn(1000))
-			key := rand.Intn(1000)
-			target[0] = map[any]any{key: impl}
-			value := map[any]any{key: testImpl(rand.Intn(1000))}
-			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
-			if target[0][key] != value[key] {
-				t.Error("target value does not meet expectation", target, value)
-			}
-			reset.Reset()
-			if target[0][key] != impl {
-				t.Error("target value does not meet expectation", target, impl)
-			}
+	t.Run("参数规则过少", func(t *testing.T) {
+		logf := func(string, ...any) error { return nil }
+		if _, err := mvt.TryFuncOuts(&logf, []mvt.OutValue{{Args: []any{}}}); !errors.Is(err, mvt.ErrArgsCountMismatch) {
+			t.Error("no ErrArgsCountMismatch returned", err)
 		}
 	})
 
-	t.Run("测试序列 6", func(t *testing.T) {
+	t.Run("Do 接收展开的参数", func(t *testing.T) {
+		hook := func(name string, values ...int) int { return 0 }
 		for range 100 {
-			var target [1]map[any]any = [1]map[any]any{nil}
-			impl := testImpl(rand.Intn(1000))
-			key := rand.Intn(1000)
-			target[0] = map[any]any{key: impl}
-			value := map[any]any{key: testImpl(rand.Intn(1000))}
-			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
-			if target[0][key] != value[key] {
-				t.Error("target value does not meet expectation", target, value)
+			values := []int{rand.Intn(1000), rand.Intn(1000), rand.Intn(1000)}
+			reset := mvt.FuncOuts(&hook, []mvt.OutValue{{Do: func(args []any) []any {
+				sum := 0
+				for _, v := range args[1:] {
+					sum += v.(int)
+				}
+				return []any{sum}
+			}}})
+			if v := hook("sum", values...); v != values[0]+values[1]+values[2] {
+				t.Error("values func returned does not meet expectation", v, values)
 			}
 			reset.Reset()
-			if target[0][key] != impl {
-				t.Error("target value does not meet expectation", target, impl)
-			}
 		}
 	})
 
-	t.Run("测试序列 7", func(t *testing.T) {
+	t.Run("调用原函数", func(t *testing.T) {
+		hook := func(values ...int) int { return len(values) }
 		for range 100 {
-			var target []map[any]any = []map[any]any{nil}
-			key := rand.Intn(1000)
-			value := map[any]any{key: testImpl(rand.Intn(1000))}
-			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
-			if target[0][key] != value[key] {
-				t.Error("target value does not meet expectation", target, value)
+			values := make([]int, rand.Intn(5))
+			reset := mvt.FuncOuts(&hook, nil)
+			if v := hook(values...); v != len(values) {
+				t.Error("values func returned does not meet expectation", v, len(values))
 			}
 			reset.Reset()
-			if target[0] != nil {
-				t.Error("target value does not meet expectation", target)
-			}
 		}
 	})
 
-	t.Run("测试序列 8", func(t *testing.T) {
+	t.Run("替换方法值", func(t *testing.T) {
+		logger := &testLogger{}
+		s := struct{ logf func(string, ...any) error }{logger.logf}
 		for range 100 {
-			var target [1]map[any]any = [1]map[any]any{nil}
-			key := rand.Intn(1000)
-			value := map[any]any{key: testImpl(rand.Intn(1000))}
-			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
-			if target[0][key] != value[key] {
-				t.Error("target value does not meet expectation", target, value)
+			value := rand.Intn(1000)
+			var reset mvt.FuncResetter
+			reset = mvt.Chain(&s).Elem().FieldByName("logf").SetFuncOuts([]mvt.OutValue{{Do: func(args []any) []any {
+				return []any{reset.Original().(func(string, ...any) error)("stub: "+args[0].(string), args[1:]...)}
+			}}})
+			if err := s.logf("%d", value); err != nil {
+				t.Error("error occurred", err)
+			}
+			if want := fmt.Sprintf("stub: %d", value); logger.last != want {
+				t.Error("logger does not meet expectation", logger.last, want)
 			}
 			reset.Reset()
-			if target[0] != nil {
-				t.Error("target value does not meet expectation", target)
+			_ = s.logf("%d", value)
+			if want := fmt.Sprint(value); logger.last != want {
+				t.Error("logger does not meet expectation", logger.last, want)
 			}
 		}
 	})
+}
 
-	t.Run("测试序列 9", func(t *testing.T) {
+func TestFunc(t *testing.T) {
+	t.Run("替换函数", func(t *testing.T) {
 		for range 100 {
-			var target []*any = []*any{nil}
 			value := rand.Intn(1000)
-			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
-			if *(target[0]) != value {
-				t.Error("target value does not meet expectation", target, value)
+			reset, err := mvt.TryFunc(testFormat, func(v int) string { return fmt.Sprint(v + value) })
+			if errors.Is(err, mvt.ErrFuncPatchUnsupported) {
+				t.Skip(err)
+			}
+			if err != nil {
+				t.Fatal("error occurred", err)
+			}
+			if v := testFormat(1); v != fmt.Sprint(value+1) {
+				t.Error("func returned does not meet expectation", v, value+1)
 			}
 			reset.Reset()
-			if target[0] != nil {
-				t.Error("target value does not meet expectation", target)
+			if v := testFormat(1); v != "value: 1" {
+				t.Error("func returned does not meet expectation", v)
 			}
 		}
 	})
 
-	t.Run("测试序列 10", func(t *testing.T) {
+	t.Run("替换方法", func(t *testing.T) {
 		for range 100 {
-			var target [1]*any = [1]*any{nil}
 			value := rand.Intn(1000)
-			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
-			if *(target[0]) != value {
-				t.Error("target value does not meet expectation", target, value)
+			c := &testCounter{}
+			reset, err := mvt.TryFunc((*testCounter).add, func(c *testCounter, n int) int {
+				c.n -= n
+				return value
+			})
+			if errors.Is(err, mvt.ErrFuncPatchUnsupported) {
+				t.Skip(err)
+			}
+			if err != nil {
+				t.Fatal("error occurred", err)
+			}
+			if v := c.add(1); v != value || c.n != -1 {
+				t.Error("method returned does not meet expectation", v, c.n)
 			}
 			reset.Reset()
-			if target[0] != nil {
-				t.Error("target value does not meet expectation", target)
+			if v := c.add(2); v != 1 || c.n != 1 {
+				t.Error("method returned does not meet expectation", v, c.n)
 			}
 		}
 	})
 
-	t.Run("测试序列 11", func(t *testing.T) {
+	t.Run("重复替换", func(t *testing.T) {
+		if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+			t.Skip(mvt.ErrFuncPatchUnsupported)
+		}
+		g := &mvt.Group{}
+		for i := range 3 {
+			g.Func(testFormat, func(int) string { return fmt.Sprint(i) })
+		}
+		if v := testFormat(1); v != "2" {
+			t.Error("func returned does not meet expectation", v)
+		}
+		g.Reset()
+		if v := testFormat(1); v != "value: 1" {
+			t.Error("func returned does not meet expectation", v)
+		}
+	})
+
+	t.Run("乱序回退", func(t *testing.T) {
+		if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+			t.Skip(mvt.ErrFuncPatchUnsupported)
+		}
 		for range 100 {
-			var impl any = testImpl(rand.Intn(1000))
-			var target []*any = []*any{&impl}
-			value := testImpl(rand.Intn(1000))
-			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
-			if *(target[0]) != value {
-				t.Error("target value does not meet expectation", target, value)
+			resets := make([]mvt.Resetter, 3)
+			for i := range resets {
+				resets[i] = mvt.Func(testFormat, func(int) string { return fmt.Sprint(i) })
 			}
-			reset.Reset()
-			if *(target[0]) != impl {
-				t.Error("target value does not meet expectation", target)
+			order := rand.Perm(len(resets))
+			for i, v := range order[:len(order)-1] {
+				resets[v].Reset()
+				if v := testFormat(1); v != fmt.Sprint(slices.Max(order[i+1:])) {
+					t.Error("func returned does not meet expectation", v, order)
+				}
+			}
+			resets[order[len(order)-1]].Reset()
+			if v := testFormat(1); v != "value: 1" {
+				t.Error("func returned does not meet expectation", v, order)
 			}
 		}
 	})
 
-	t.Run("测试序列 12", func(t *testing.T) {
+	t.Run("类型不一致", func(t *testing.T) {
+		if _, err := mvt.TryFunc(testFormat, func(int64) string { return "" }); !errors.Is(err, mvt.ErrIncompatibleTypeAssignment) {
+			t.Error("no ErrIncompatibleTypeAssignment returned", err)
+		}
+		if _, err := mvt.TryFunc(testFormat, nil); !errors.Is(err, mvt.ErrIncompatibleTypeAssignment) {
+			t.Error("no ErrIncompatibleTypeAssignment returned", err)
+		}
+		if _, err := mvt.TryFunc(1, 1); !errors.Is(err, mvt.ErrTargetIsNotFunc) {
+			t.Error("no ErrTargetIsNotFunc returned", err)
+		}
+	})
+
+	t.Run("不能替换", func(t *testing.T) {
+		c := &testCounter{}
+		if _, err := mvt.TryFunc(c.add, func(int) int { return 0 }); !errors.Is(err, mvt.ErrFuncCannotBePatched) &&
+			!errors.Is(err, mvt.ErrFuncPatchUnsupported) {
+			t.Error("no ErrFuncCannotBePatched returned", err)
+		}
+		if _, err := mvt.TryFunc(testTiny, func() {}); !errors.Is(err, mvt.ErrFuncCannotBePatched) &&
+			!errors.Is(err, mvt.ErrFuncPatchUnsupported) {
+			t.Error("no ErrFuncCannotBePatched returned", err)
+		}
+	})
+}
+
+func TestSymbol(t *testing.T) {
+	const name = "gitee.com/ivfzhou/modify-variables-temporarily/v3_test.testSymbol"
+
+	t.Run("符号不存在", func(t *testing.T) {
+		if _, _, err := mvt.Symbol(name + "NotExist"); !errors.Is(err, mvt.ErrSymbolNotFound) {
+			t.Error("no ErrSymbolNotFound returned", err)
+		}
+		if _, err := mvt.SymbolOf[int](name + "NotExist"); !errors.Is(err, mvt.ErrSymbolNotFound) {
+			t.Error("no ErrSymbolNotFound returned", err)
+		}
+	})
+
+	if file, err := elf.Open(os.Args[0]); err != nil {
+		t.Skip(err)
+	} else if _, err = file.Symbols(); err != nil {
+		_ = file.Close()
+		t.Skip("run with -ldflags='-s=false -w=false' to test symbols.", err)
+	} else {
+		_ = file.Close()
+	}
+
+	t.Run("查找变量", func(t *testing.T) {
 		for range 100 {
-			var impl any = testImpl(rand.Intn(1000))
-			var target [1]*any = [1]*any{&impl}
-			value := testImpl(rand.Intn(1000))
-			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
-			if *(target[0]) != value {
-				t.Error("target value does not meet expectation", target, value)
+			value := rand.Intn(1000)
+			ptr, typ, err := mvt.Symbol(name)
+			if err != nil {
+				t.Fatal("error occurred", err)
+			}
+			if typ != reflect.TypeOf(testSymbol) || ptr != &testSymbol {
+				t.Fatal("symbol does not meet expectation", typ, ptr)
+			}
+			reset := mvt.Var(ptr, testSymbolStruct{value: value})
+			if testSymbol.value != value {
+				t.Error("value does not meet expectation", testSymbol.value, value)
 			}
 			reset.Reset()
-			if *(target[0]) != impl {
-				t.Error("target value does not meet expectation", target)
+			if testSymbol.value != 1 {
+				t.Error("value does not meet expectation", testSymbol.value)
 			}
 		}
 	})
 
-	t.Run("测试 map 1", func(t *testing.T) {
+	t.Run("指定类型", func(t *testing.T) {
+		ptr, err := mvt.SymbolOf[testSymbolStruct](name)
+		if err != nil || ptr != &testSymbol {
+			t.Error("symbol does not meet expectation", ptr, err)
+		}
+		if _, err = mvt.SymbolOf[int8](name); !errors.Is(err, mvt.ErrIncompatibleTypeAssignment) {
+			t.Error("no ErrIncompatibleTypeAssignment returned", err)
+		}
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Run("目标不合法", func(t *testing.T) {
+		if _, err := mvt.TrySnapshot(nil); !errors.Is(err, mvt.ErrTargetCannotBeNil) {
+			t.Error("no ErrTargetCannotBeNil returned", err)
+		}
+		if _, err := mvt.TrySnapshot(1); !errors.Is(err, mvt.ErrTargetIsNotPointer) {
+			t.Error("no ErrTargetIsNotPointer returned", err)
+		}
+		if _, err := mvt.TrySnapshot((*int)(nil)); !errors.Is(err, mvt.ErrTargetCannotBeNilType) {
+			t.Error("no ErrTargetCannotBeNilType returned", err)
+		}
+	})
+
+	t.Run("深度回退", func(t *testing.T) {
 		for range 100 {
-			var data map[any]any
-			key := rand.Intn(1000)
 			value := rand.Intn(1000)
-			reset := mvt.Chain(&data).Elem().MapValue(key).Set(value)
-			if data[key] != value {
-				t.Error("target value does not meet expectation", data, value)
+			inner := &testSnapshotNode{value: value}
+			fn := func() int { return value }
+			ch := make(chan int)
+			data := &testSnapshotNode{
+				value:   value,
+				next:    inner,
+				m:       map[string]*testSnapshotNode{"inner": inner},
+				s:       []int{value, value + 1},
+				a:       [2]*testSnapshotNode{inner},
+				i:       &testSnapshotNode{value: value},
+				fn:      fn,
+				ch:      ch,
+				Version: value,
+			}
+			data.self = data
+			s := data.s
+
+			reset := mvt.Snapshot(data)
+			data.value++
+			data.next.value++
+			data.m["inner"].m = map[string]*testSnapshotNode{}
+			data.m["new"] = nil
+			delete(data.m, "inner")
+			data.s[1] = 0
+			data.s = append(data.s, 0)
+			data.a[1] = &testSnapshotNode{}
+			data.i.(*testSnapshotNode).value++
+			data.fn = nil
+			data.ch = nil
+			data.self = nil
+			data.Version++
+			reset.Reset()
+
+			if data.value != value || data.next != inner || inner.value != value || inner.m != nil {
+				t.Error("node does not meet expectation", data.value, inner.value, inner.m)
+			}
+			if len(data.m) != 1 || data.m["inner"] != inner {
+				t.Error("map does not meet expectation", data.m)
+			}
+			if len(data.s) != 2 || &data.s[0] != &s[0] || s[1] != value+1 {
+				t.Error("slice does not meet expectation", data.s)
+			}
+			if data.a[0] != inner || data.a[1] != nil || data.i.(*testSnapshotNode).value != value {
+				t.Error("array or interface does not meet expectation", data.a, data.i)
 			}
+			if data.fn() != value || data.ch != ch || data.self != data || data.Version != value {
+				t.Error("shallow values do not meet expectation", data.ch, data.self, data.Version)
+			}
+		}
+	})
+
+	t.Run("映射中的值", func(t *testing.T) {
+		for range 100 {
+			value := rand.Intn(1000)
+			m := map[int]testSnapshotNode{value: {next: &testSnapshotNode{value: value}}}
+			next := m[value].next
+			reset := mvt.Snapshot(&m)
+			m[value].next.value++
+			m[value+1] = testSnapshotNode{}
 			reset.Reset()
-			if data != nil {
-				t.Error("target value does not meet expectation", data)
+			if len(m) != 1 || m[value].next != next || next.value != value {
+				t.Error("map does not meet expectation", m)
 			}
 		}
 	})
 
-	t.Run("测试 map 2", func(t *testing.T) {
+	t.Run("映射的键值", func(t *testing.T) {
 		for range 100 {
-			var data = map[any]any{}
-			key := rand.Intn(1000)
 			value := rand.Intn(1000)
-			reset := mvt.Chain(&data).Elem().MapValue(key).Set(value)
-			if data[key] != value {
-				t.Error("target value does not meet expectation", data, value)
+			m := map[int]string{value: "a", value + 1: "b"}
+			reset := mvt.Snapshot(&m)
+			delete(m, value)
+			m[value+2] = "a"
+			reset.Reset()
+			if !reflect.DeepEqual(m, map[int]string{value: "a", value + 1: "b"}) {
+				t.Error("map does not meet expectation", m)
 			}
+			reset = mvt.Snapshot(&m)
+			m[value+1] = "c"
 			reset.Reset()
-			if data[key] != nil {
-				t.Error("target value does not meet expectation", data)
+			if !reflect.DeepEqual(m, map[int]string{value: "a", value + 1: "b"}) {
+				t.Error("map does not meet expectation", m)
+			}
+			reset = mvt.Snapshot(&m)
+			reset.Reset()
+			if !reflect.DeepEqual(m, map[int]string{value: "a", value + 1: "b"}) {
+				t.Error("map does not meet expectation", m)
 			}
 		}
 	})
 
-	t.Run("测试 map 3", func(t *testing.T) {
+	t.Run("包含类型描述符", func(t *testing.T) {
 		for range 100 {
-			key := rand.Intn(1000)
-			impl := testImpl(rand.Intn(1000))
-			var data = map[any]any{key: impl}
-			value := testImpl(rand.Intn(1000))
-			reset := mvt.Chain(&data).Elem().MapValue(key).Elem().Set(value)
-			if data[key] != value {
-				t.Error("target value does not meet expectation", data, value)
+			value := rand.Intn(1000)
+			target := &struct {
+				t reflect.Type
+				n int
+			}{reflect.TypeOf(0), value}
+			reset := mvt.Snapshot(target)
+			target.n = value + 1
+			target.t = reflect.TypeOf("")
+			reset.Reset()
+			if target.n != value || target.t != reflect.TypeOf(0) {
+				t.Error("target does not meet expectation", target)
+			}
+		}
+	})
+}
+
+func TestChain(t *testing.T) {
+	t.Run("测试指针 1", func(t *testing.T) {
+		for range 100 {
+			var target any
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&target).Elem().Set(value)
+			if target != value {
+				t.Log("target value does not meet expectation", target, value)
 			}
 			reset.Reset()
-			if data[key] != impl {
-				t.Error("target value does not meet expectation", data, impl)
+			if target != nil {
+				t.Error("target value does not meet expectation", target)
 			}
 		}
 	})
 
-	t.Run("测试 map 4", func(t *testing.T) {
+	t.Run("测试指针 2", func(t *testing.T) {
 		for range 100 {
-			key := rand.Intn(1000)
-			var data = map[any]testInterface{key: nil}
-			value := testImpl(rand.Intn(1000))
-			reset := mvt.Chain(&data).Elem().MapValue(key).Set(value)
-			if data[key] != value {
-				t.Error("target value does not meet expectation", data, value)
+			var target *any
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&target).Elem().Elem().Set(value)
+			if *target != value {
+				t.Log("target value does not meet expectation", target, value)
 			}
 			reset.Reset()
-			if data[key] != nil {
-				t.Error("target value does not meet expectation", data)
+			if target != nil {
+				t.Error("target value does not meet expectation", target)
 			}
 		}
 	})
 
-	t.Run("测试 map 5", func(t *testing.T) {
+	t.Run("测试指针 3", func(t *testing.T) {
 		for range 100 {
+			var target *map[any]any
 			key := rand.Intn(1000)
-			var impl testInterface = testImpl(rand.Intn(1000))
-			var data = map[any]*testInterface{key: &impl}
-			value := testImpl2(rand.Intn(1000))
-			reset := mvt.Chain(&data).Elem().MapValue(key).Elem().Set(value)
-			if *(data[key]) != value {
-				t.Error("target value does not meet expectation", data, value)
+			value := map[any]any{key: rand.Intn(1000)}
+			reset := mvt.Chain(&target).Elem().Elem().Set(value)
+			if (*target)[key] != value[key] {
+				t.Log("target value does not meet expectation", target, value)
 			}
 			reset.Reset()
-			if *(data[key]) != impl {
-				t.Error("target value does not meet expectation", data, impl)
+			if target != nil {
+				t.Error("target value does not meet expectation", target)
 			}
 		}
 	})
 
-	t.Run("测试 map 6", func(t *testing.T) {
+	t.Run("测试指针 4", func(t *testing.T) {
 		for range 100 {
-			var data = map[any]map[any]any{}
-			key := rand.Intn(1000)
-			value := rand.Intn(1000)
-			reset := mvt.Chain(&data).Elem().MapValue(key).MapValue(key).Set(value)
-			if data[key][key] != value {
-				t.Error("target value does not meet expectation", data, value)
+			var target *testInterface
+			value := testImpl(rand.Intn(1000))
+			reset := mvt.Chain(&target).Elem().Elem().Set(value)
+			if *target != value {
+				t.Log("target value does not meet expectation", target, value)
 			}
 			reset.Reset()
-			if data[key] != nil {
-				t.Error("target value does not meet expectation", data)
+			if target != nil {
+				t.Error("target value does not meet expectation", target)
 			}
 		}
 	})
 
-	t.Run("测试函数 1", func(t *testing.T) {
+	t.Run("测试指针 5", func(t *testing.T) {
 		for range 100 {
-			var data = func() any { return nil }
-			value := rand.Intn(1000)
-			reset := mvt.Chain(&data).Elem().SetFuncOuts([]mvt.OutValue{
-				{
-					Values: []any{value},
-				},
-			})
-			result := data()
-			if result != value {
-				t.Error("target value does not meet expectation", result, value)
+			var impl testInterface = testImpl(rand.Intn(1000))
+			var target *testInterface = &impl
+			value := testImpl(rand.Intn(1000))
+			reset := mvt.Chain(&target).Elem().Elem().Elem().Set(value)
+			if *target != value {
+				t.Log("target value does not meet expectation", target, value)
 			}
 			reset.Reset()
-			result2 := data()
-			if result2 != nil {
-				t.Error("target value does not meet expectation", result2)
+			if *target != impl {
+				t.Error("target value does not meet expectation", target)
 			}
 		}
 	})
 
-	t.Run("测试函数 2", func(t *testing.T) {
+	t.Run("测试结构体 1", func(t *testing.T) {
 		for range 100 {
-			var data = []any{func() any { return nil }}
-			value := rand.Intn(1000)
-			reset := mvt.Chain(&data).Elem().Index(0).Elem().SetFuncOuts([]mvt.OutValue{
-				{
-					Values: []any{value},
-				},
-			})
-			result := data[0].(func() any)()
-			if result != value {
-				t.Error("target value does not meet expectation", result, value)
+			var target testStruct
+			chainer := mvt.Chain(&target).Elem()
+			if rand.Intn(2) > 0 {
+				chainer = chainer.Field(0)
+			} else {
+				chainer = chainer.FieldByName("unexportedField")
+			}
+			value := testImpl(rand.Intn(1000))
+			reset := chainer.Set(value)
+			if target.unexportedField != value {
+				t.Error("target value does not meet expectation", target, value)
 			}
 			reset.Reset()
-			result2 := data[0].(func() any)()
-			if result2 != nil {
-				t.Error("target value does not meet expectation", result2)
+			if target.unexportedField != nil {
+				t.Error("target value does not meet expectation", target.unexportedField)
 			}
 		}
 	})
 
-	t.Run("测试函数 3", func(t *testing.T) {
+	t.Run("测试结构体 2", func(t *testing.T) {
 		for range 100 {
-			var fn any = func() any { return nil }
-			var data *any = &fn
-			value := rand.Intn(1000)
-			reset := mvt.Chain(&data).Elem().Elem().Elem().SetFuncOuts([]mvt.OutValue{
-				{
-					Values: []any{value},
-				},
-			})
-			result := (*data).(func() any)()
-			if result != value {
-				t.Error("target value does not meet expectation", result, value)
+			var target testStruct
+			impl := testImpl(rand.Intn(1000))
+			target.unexportedField = impl
+			chainer := mvt.Chain(&target).Elem()
+			if rand.Intn(2) > 0 {
+				chainer = chainer.Field(0)
+			} else {
+				chainer = chainer.FieldByName("unexportedField")
+			}
+			value := testImpl(rand.Intn(1000))
+			reset := chainer.Elem().Set(value)
+			if target.unexportedField != value {
+				t.Error("target value does not meet expectation", target, value)
 			}
 			reset.Reset()
-			result2 := (*data).(func() any)()
-			if result2 != nil {
-				t.Error("target value does not meet expectation", result2)
+			if target.unexportedField != impl {
+				t.Error("target value does not meet expectation", target.unexportedField)
 			}
 		}
 	})
 
-	t.Run("复杂链路", func(t *testing.T) {
-		type st2 struct {
-			field [1]map[any]any
+	t.Run("测试结构体 3", func(t *testing.T) {
+		for range 100 {
+			var target testStruct
+			chainer := mvt.Chain(&target).Elem()
+			if rand.Intn(2) > 0 {
+				chainer = chainer.Field(2)
+			} else {
+				chainer = chainer.FieldByName("unexportedField3")
+			}
+			value := testImpl(rand.Intn(1000))
+			reset := chainer.Elem().Set(value)
+			if *target.unexportedField3 != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target.unexportedField3 != nil {
+				t.Error("target value does not meet expectation", target.unexportedField)
+			}
 		}
-		type st struct {
-			field [1]*st2
+	})
+
+	t.Run("测试结构体 4", func(t *testing.T) {
+		for range 100 {
+			var target testStruct
+			impl := testImpl(rand.Intn(1000))
+			target.unexportedField3 = &impl
+			chainer := mvt.Chain(&target).Elem()
+			if rand.Intn(2) > 0 {
+				chainer = chainer.Field(2)
+			} else {
+				chainer = chainer.FieldByName("unexportedField3")
+			}
+			value := testImpl(rand.Intn(1000))
+			reset := chainer.Elem().Set(value)
+			if *target.unexportedField3 != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if *target.unexportedField3 != impl {
+				t.Error("target value does not meet expectation", target.unexportedField, impl)
+			}
 		}
-		var data map[any][]st
-		key := rand.Intn(1000)
-		defer mvt.Chain(&data).Elem().MapValue(key).Set(make([]st, 1)).Reset()
-		value := rand.Intn(1000)
-		defer mvt.Chain(&data).Elem().MapValue(key).Index(0).Field(0).Index(0).Elem().Field(0).Index(0).
-			MapValue(key).Set(value).Reset()
-		if data[key][0].field[0].field[0][key] != value {
-			t.Error("target value does not meet expectation", data, value)
+	})
+
+	t.Run("测试结构体 5", func(t *testing.T) {
+		for range 100 {
+			var target testStruct
+			chainer := mvt.Chain(&target).Elem()
+			if rand.Intn(2) > 0 {
+				chainer = chainer.Field(3)
+			} else {
+				chainer = chainer.FieldByName("unexportedField4")
+			}
+			key := rand.Intn(1000)
+			value := map[any]any{key: testImpl(rand.Intn(1000))}
+			reset := chainer.Set(value)
+			if target.unexportedField4[key] != value[key] {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target.unexportedField4 != nil {
+				t.Error("target value does not meet expectation", target.unexportedField)
+			}
+		}
+	})
+
+	t.Run("测试结构体 6", func(t *testing.T) {
+		for range 100 {
+			var target testStruct
+			key := rand.Intn(1000)
+			impl := testImpl(rand.Intn(1000))
+			target.unexportedField4 = map[any]any{key: impl}
+			chainer := mvt.Chain(&target).Elem()
+			if rand.Intn(2) > 0 {
+				chainer = chainer.Field(3)
+			} else {
+				chainer = chainer.FieldByName("unexportedField4")
+			}
+			value := map[any]any{key: testImpl(rand.Intn(1000))}
+			reset := chainer.Set(value)
+			if target.unexportedField4[key] != value[key] {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target.unexportedField4[key] != impl {
+				t.Error("target value does not meet expectation", target.unexportedField, impl)
+			}
+		}
+	})
+
+	t.Run("测试序列 1", func(t *testing.T) {
+		for range 100 {
+			var target []any = []any{nil}
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
+			if target[0] != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0] != nil {
+				t.Error("target value does not meet expectation", target)
+			}
+		}
+	})
+
+	t.Run("测试序列 2", func(t *testing.T) {
+		for range 100 {
+			var target [1]any = [1]any{nil}
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
+			if target[0] != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0] != nil {
+				t.Error("target value does not meet expectation", target)
+			}
+		}
+	})
+
+	t.Run("测试序列 3", func(t *testing.T) {
+		for range 100 {
+			var target []testInterface = []testInterface{testInterface(nil)}
+			impl := testImpl(rand.Intn(1000))
+			target[0] = impl
+			value := testImpl(rand.Intn(1000))
+			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
+			if target[0] != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0] != impl {
+				t.Error("target value does not meet expectation", target, impl)
+			}
+		}
+	})
+
+	t.Run("测试序列 4", func(t *testing.T) {
+		for range 100 {
+			var target [1]testInterface = [1]testInterface{testInterface(nil)}
+			impl := testImpl(rand.Intn(1000))
+			target[0] = impl
+			value := testImpl(rand.Intn(1000))
+			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
+			if target[0] != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0] != impl {
+				t.Error("target value does not meet expectation", target, impl)
+			}
+		}
+	})
+
+	t.Run("测试序列 5", func(t *testing.T) {
+		for range 100 {
+			var target []map[any]any = []map[any]any{nil}
+			impl := testImpl(rand.Intn(1000))
+			key := rand.Intn(1000)
+			target[0] = map[any]any{key: impl}
+			value := map[any]any{key: testImpl(rand.Intn(1000))}
+			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
+			if target[0][key] != value[key] {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0][key] != impl {
+				t.Error("target value does not meet expectation", target, impl)
+			}
+		}
+	})
+
+	t.Run("测试序列 6", func(t *testing.T) {
+		for range 100 {
+			var target [1]map[any]any = [1]map[any]any{nil}
+			impl := testImpl(rand.Intn(1000))
+			key := rand.Intn(1000)
+			target[0] = map[any]any{key: impl}
+			value := map[any]any{key: testImpl(rand.Intn(1000))}
+			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
+			if target[0][key] != value[key] {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0][key] != impl {
+				t.Error("target value does not meet expectation", target, impl)
+			}
+		}
+	})
+
+	t.Run("测试序列 7", func(t *testing.T) {
+		for range 100 {
+			var target []map[any]any = []map[any]any{nil}
+			key := rand.Intn(1000)
+			value := map[any]any{key: testImpl(rand.Intn(1000))}
+			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
+			if target[0][key] != value[key] {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0] != nil {
+				t.Error("target value does not meet expectation", target)
+			}
+		}
+	})
+
+	t.Run("测试序列 8", func(t *testing.T) {
+		for range 100 {
+			var target [1]map[any]any = [1]map[any]any{nil}
+			key := rand.Intn(1000)
+			value := map[any]any{key: testImpl(rand.Intn(1000))}
+			reset := mvt.Chain(&target).Elem().Index(0).Set(value)
+			if target[0][key] != value[key] {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0] != nil {
+				t.Error("target value does not meet expectation", target)
+			}
+		}
+	})
+
+	t.Run("测试序列 9", func(t *testing.T) {
+		for range 100 {
+			var target []*any = []*any{nil}
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
+			if *(target[0]) != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0] != nil {
+				t.Error("target value does not meet expectation", target)
+			}
+		}
+	})
+
+	t.Run("测试序列 10", func(t *testing.T) {
+		for range 100 {
+			var target [1]*any = [1]*any{nil}
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
+			if *(target[0]) != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if target[0] != nil {
+				t.Error("target value does not meet expectation", target)
+			}
+		}
+	})
+
+	t.Run("测试序列 11", func(t *testing.T) {
+		for range 100 {
+			var impl any = testImpl(rand.Intn(1000))
+			var target []*any = []*any{&impl}
+			value := testImpl(rand.Intn(1000))
+			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
+			if *(target[0]) != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if *(target[0]) != impl {
+				t.Error("target value does not meet expectation", target)
+			}
+		}
+	})
+
+	t.Run("测试序列 12", func(t *testing.T) {
+		for range 100 {
+			var impl any = testImpl(rand.Intn(1000))
+			var target [1]*any = [1]*any{&impl}
+			value := testImpl(rand.Intn(1000))
+			reset := mvt.Chain(&target).Elem().Index(0).Elem().Set(value)
+			if *(target[0]) != value {
+				t.Error("target value does not meet expectation", target, value)
+			}
+			reset.Reset()
+			if *(target[0]) != impl {
+				t.Error("target value does not meet expectation", target)
+			}
+		}
+	})
+
+	t.Run("测试 map 1", func(t *testing.T) {
+		for range 100 {
+			var data map[any]any
+			key := rand.Intn(1000)
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&data).Elem().MapValue(key).Set(value)
+			if data[key] != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset.Reset()
+			if data != nil {
+				t.Error("target value does not meet expectation", data)
+			}
+		}
+	})
+
+	t.Run("测试 map 2", func(t *testing.T) {
+		for range 100 {
+			var data = map[any]any{}
+			key := rand.Intn(1000)
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&data).Elem().MapValue(key).Set(value)
+			if data[key] != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset.Reset()
+			if data[key] != nil {
+				t.Error("target value does not meet expectation", data)
+			}
+		}
+	})
+
+	t.Run("测试 map 3", func(t *testing.T) {
+		for range 100 {
+			key := rand.Intn(1000)
+			impl := testImpl(rand.Intn(1000))
+			var data = map[any]any{key: impl}
+			value := testImpl(rand.Intn(1000))
+			reset := mvt.Chain(&data).Elem().MapValue(key).Elem().Set(value)
+			if data[key] != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset.Reset()
+			if data[key] != impl {
+				t.Error("target value does not meet expectation", data, impl)
+			}
+		}
+	})
+
+	t.Run("测试 map 4", func(t *testing.T) {
+		for range 100 {
+			key := rand.Intn(1000)
+			var data = map[any]testInterface{key: nil}
+			value := testImpl(rand.Intn(1000))
+			reset := mvt.Chain(&data).Elem().MapValue(key).Set(value)
+			if data[key] != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset.Reset()
+			if data[key] != nil {
+				t.Error("target value does not meet expectation", data)
+			}
+		}
+	})
+
+	t.Run("测试 map 5", func(t *testing.T) {
+		for range 100 {
+			key := rand.Intn(1000)
+			var impl testInterface = testImpl(rand.Intn(1000))
+			var data = map[any]*testInterface{key: &impl}
+			value := testImpl2(rand.Intn(1000))
+			reset := mvt.Chain(&data).Elem().MapValue(key).Elem().Set(value)
+			if *(data[key]) != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset.Reset()
+			if *(data[key]) != impl {
+				t.Error("target value does not meet expectation", data, impl)
+			}
+		}
+	})
+
+	t.Run("测试 map 6", func(t *testing.T) {
+		for range 100 {
+			var data = map[any]map[any]any{}
+			key := rand.Intn(1000)
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&data).Elem().MapValue(key).MapValue(key).Set(value)
+			if data[key][key] != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset.Reset()
+			if data[key] != nil {
+				t.Error("target value does not meet expectation", data)
+			}
+		}
+	})
+
+	t.Run("测试函数 1", func(t *testing.T) {
+		for range 100 {
+			var data = func() any { return nil }
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&data).Elem().SetFuncOuts([]mvt.OutValue{
+				{
+					Values: []any{value},
+				},
+			})
+			result := data()
+			if result != value {
+				t.Error("target value does not meet expectation", result, value)
+			}
+			reset.Reset()
+			result2 := data()
+			if result2 != nil {
+				t.Error("target value does not meet expectation", result2)
+			}
+		}
+	})
+
+	t.Run("测试函数 2", func(t *testing.T) {
+		for range 100 {
+			var data = []any{func() any { return nil }}
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&data).Elem().Index(0).Elem().SetFuncOuts([]mvt.OutValue{
+				{
+					Values: []any{value},
+				},
+			})
+			result := data[0].(func() any)()
+			if result != value {
+				t.Error("target value does not meet expectation", result, value)
+			}
+			reset.Reset()
+			result2 := data[0].(func() any)()
+			if result2 != nil {
+				t.Error("target value does not meet expectation", result2)
+			}
+		}
+	})
+
+	t.Run("测试函数 3", func(t *testing.T) {
+		for range 100 {
+			var fn any = func() any { return nil }
+			var data *any = &fn
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&data).Elem().Elem().Elem().SetFuncOuts([]mvt.OutValue{
+				{
+					Values: []any{value},
+				},
+			})
+			result := (*data).(func() any)()
+			if result != value {
+				t.Error("target value does not meet expectation", result, value)
+			}
+			reset.Reset()
+			result2 := (*data).(func() any)()
+			if result2 != nil {
+				t.Error("target value does not meet expectation", result2)
+			}
+		}
+	})
+
+	t.Run("测试 map 键不存在", func(t *testing.T) {
+		for range 100 {
+			var data = map[any][]any{}
+			key := rand.Intn(1000)
+			var nilKey any
+			value := rand.Intn(1000)
+			reset := mvt.Chain(&data).Elem().MapValue(key).Set([]any{value})
+			reset2 := mvt.Chain(&data).Elem().MapValue(key).Index(0).Set(value + 1)
+			reset3 := mvt.Chain(&data).Elem().MapValue(nilKey).Set(nil)
+			if data[key][0] != value+1 || len(data) != 2 {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset3.Reset()
+			reset2.Reset()
+			if data[key][0] != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset.Reset()
+			if _, ok := data[key]; ok || len(data) != 0 {
+				t.Error("target value does not meet expectation", data)
+			}
+		}
+	})
+
+	t.Run("测试 map 值是 nil", func(t *testing.T) {
+		for range 100 {
+			key := rand.Intn(1000)
+			var data = map[any]any{key: nil}
+			reset := mvt.Chain(&data).Elem().MapValue(key).Set(rand.Intn(1000))
+			reset.Reset()
+			if v, ok := data[key]; !ok || v != nil {
+				t.Error("target value does not meet expectation", data)
+			}
+		}
+	})
+
+	t.Run("测试删除键", func(t *testing.T) {
+		for range 100 {
+			key := rand.Intn(1000)
+			value := rand.Intn(1000)
+			var data = &testStruct{unexportedField4: map[any]any{key: value}}
+			reset := mvt.Chain(&data).Elem().Elem().FieldByName("unexportedField4").DeleteKey(key)
+			if _, ok := data.unexportedField4[key]; ok {
+				t.Error("target value does not meet expectation", data)
+			}
+			reset.Reset()
+			if v, ok := data.unexportedField4[key]; !ok || v != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+
+			m := map[any]any{key: value}
+			reset = mvt.Chain(m).DeleteKey(key)
+			if len(m) != 0 {
+				t.Error("target value does not meet expectation", m)
+			}
+			reset.Reset()
+			if m[key] != value {
+				t.Error("target value does not meet expectation", m, value)
+			}
+		}
+	})
+
+	t.Run("测试删除不存在的键", func(t *testing.T) {
+		for range 100 {
+			var data *testStruct
+			_, err := mvt.Chain(&data).Elem().Elem().FieldByName("unexportedField4").TryDeleteKey(rand.Intn(1000))
+			if !errors.Is(err, mvt.ErrMapKeyNotFound) {
+				t.Error("no ErrMapKeyNotFound returned", err)
+			}
+			if data != nil {
+				t.Error("target value does not meet expectation", data)
+			}
+			_, err = mvt.Chain(&data).Elem().TryDeleteKey(rand.Intn(1000))
+			if !errors.Is(err, mvt.ErrTargetIsNotMap) {
+				t.Error("no ErrTargetIsNotMap returned", err)
+			}
+		}
+	})
+
+	t.Run("复杂链路", func(t *testing.T) {
+		type st2 struct {
+			field [1]map[any]any
+		}
+		type st struct {
+			field [1]*st2
+		}
+		var data map[any][]st
+		key := rand.Intn(1000)
+		defer mvt.Chain(&data).Elem().MapValue(key).Set(make([]st, 1)).Reset()
+		value := rand.Intn(1000)
+		defer mvt.Chain(&data).Elem().MapValue(key).Index(0).Field(0).Index(0).Elem().Field(0).Index(0).
+			MapValue(key).Set(value).Reset()
+		if data[key][0].field[0].field[0][key] != value {
+			t.Error("target value does not meet expectation", data, value)
+		}
+	})
+}
+
+func TestT(t *testing.T) {
+	t.Run("回退注册到 Cleanup", func(t *testing.T) {
+		for range 100 {
+			originalValue := rand.Intn(1000)
+			target := originalValue
+			s := testStruct{unexportedField2: testImpl(originalValue)}
+			arr := []int{originalValue}
+			m := map[int]int{}
+			fn := func() int { return originalValue }
+			var data *testStruct
+			newValue := rand.Intn(1000)
+			t.Run("", func(t *testing.T) {
+				mt := mvt.T(t)
+				mt.Var(&target, newValue)
+				mt.Var(&target, newValue+1)
+				mt.FieldByName(&s, "unexportedField2", newValue)
+				mt.Field(&s, 0, testImpl(newValue))
+				mt.Elem(arr, 0, newValue)
+				mt.Map(m, 1, newValue)
+				mt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{newValue}}})
+				mt.Chain(&data).Elem().Elem().Field(1).Set(newValue)
+				if target != newValue+1 || int(s.unexportedField2) != newValue || s.unexportedField != testImpl(newValue) ||
+					arr[0] != newValue || m[1] != newValue || fn() != newValue || int(data.unexportedField2) != newValue {
+					t.Error("target value does not meet expectation")
+				}
+			})
+			if target != originalValue || int(s.unexportedField2) != originalValue || s.unexportedField != nil ||
+				arr[0] != originalValue || len(m) != 0 || fn() != originalValue || data != nil {
+				t.Error("target value does not meet expectation", target, s, arr, m, data)
+			}
+		}
+	})
+
+	t.Run("替换函数的方式一致", func(t *testing.T) {
+		for range 100 {
+			value := rand.Intn(1000)
+			errTest := errors.New("test")
+			fnErr := func() (int, error) { return value, nil }
+			fnReturns := func() int { return value }
+			tb := &fakeTB{TB: t}
+			mvt.T(tb).FuncErr(&fnErr, errTest, 1)
+			if len(tb.cleanups) != 2 {
+				t.Error("cleanups does not meet expectation", len(tb.cleanups))
+			}
+			mvt.T(tb).FuncReturns(&fnReturns, value+1)
+			if len(tb.cleanups) != 4 {
+				t.Error("cleanups does not meet expectation", len(tb.cleanups))
+			}
+			if _, err := fnErr(); err != errTest || fnReturns() != value+1 {
+				t.Error("func returned does not meet expectation", err)
+			}
+			tb.cleanup()
+			if n, err := fnErr(); n != value || err != nil || fnReturns() != value || tb.errors != "" {
+				t.Error("func returned does not meet expectation", n, err, tb.errors)
+			}
+		}
+	})
+
+	t.Run("修改失败时终止测试", func(t *testing.T) {
+		for range 100 {
+			tb := &fakeTB{TB: t}
+			tb.run(func() {
+				var target int
+				mvt.T(tb).Var(&target, "")
+			})
+			if !strings.Contains(tb.fatal, mvt.ErrIncompatibleTypeAssignment.Error()) {
+				t.Error("no ErrIncompatibleTypeAssignment reported", tb.fatal)
+			}
+			if len(tb.cleanups) != 0 {
+				t.Error("cleanups does not meet expectation", len(tb.cleanups))
+			}
+
+			tb = &fakeTB{TB: t}
+			tb.run(func() {
+				var target []int
+				mvt.T(tb).Chain(&target).Elem().Index(1).Set(1)
+			})
+			if !strings.Contains(tb.fatal, mvt.ErrIndexOutOfBound.Error()) {
+				t.Error("no ErrIndexOutOfBound reported", tb.fatal)
+			}
+
+			tb = &fakeTB{TB: t}
+			tb.run(func() { mvt.T(tb).Chain(nil) })
+			if !strings.Contains(tb.fatal, mvt.ErrTargetCannotBeNil.Error()) {
+				t.Error("no ErrTargetCannotBeNil reported", tb.fatal)
+			}
+		}
+	})
+}
+
+func TestTry(t *testing.T) {
+	t.Run("返回错误", func(t *testing.T) {
+		var target int
+		var s testStruct
+		var fn func()
+		var data []int
+		cases := []struct {
+			fn  func() (mvt.Resetter, error)
+			err error
+		}{
+			{func() (mvt.Resetter, error) { return mvt.TryVar(nil, 0) }, mvt.ErrTargetCannotBeNil},
+			{func() (mvt.Resetter, error) { return mvt.TryVar(target, 0) }, mvt.ErrTargetIsNotPointer},
+			{func() (mvt.Resetter, error) { return mvt.TryVar(&target, "") }, mvt.ErrIncompatibleTypeAssignment},
+			{func() (mvt.Resetter, error) { return mvt.TryFieldByName(&s, "notExist", 0) }, mvt.ErrStructFieldNotFound},
+			{func() (mvt.Resetter, error) { return mvt.TryField(&s, 100, 0) }, mvt.ErrStructFieldNotFound},
+			{func() (mvt.Resetter, error) { return mvt.TryElem([]int(nil), 0, 0) }, mvt.ErrTargetCannotBeNilType},
+			{func() (mvt.Resetter, error) { return mvt.TryElem([]int{1}, 1, 0) }, mvt.ErrIndexOutOfBound},
+			{func() (mvt.Resetter, error) { return mvt.TryMap(map[int]int{1: 1}, "", 0) }, mvt.ErrInvalidMapKeyType},
+			{func() (mvt.Resetter, error) { return mvt.TryFuncOuts(&target, nil) }, mvt.ErrTargetIsNotFunc},
+			{func() (mvt.Resetter, error) { return mvt.Chain(&data).Elem().Index(0).TrySet(1) }, mvt.ErrIndexOutOfBound},
+			{func() (mvt.Resetter, error) { return mvt.Chain(&fn).Elem().Elem().TrySetFuncOuts(nil) }, mvt.ErrTargetIsNotPointerOrInterface},
+			{func() (mvt.Resetter, error) { return mvt.Chain(&target).Elem().TrySetFuncOuts(nil) }, mvt.ErrTargetIsNotFunc},
+		}
+		for i, v := range cases {
+			reset, err := v.fn()
+			if !errors.Is(err, v.err) || reset != nil {
+				t.Error("error does not meet expectation", i, err, v.err)
+			}
+		}
+		if _, err := mvt.TryChain(nil); !errors.Is(err, mvt.ErrTargetCannotBeNil) {
+			t.Error("no ErrTargetCannotBeNil returned", err)
+		}
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		for range 100 {
+			originalValue := rand.Intn(1000)
+			target := originalValue
+			newValue := rand.Intn(1000)
+			reset, err := mvt.TryVar(&target, newValue)
+			if err != nil || target != newValue {
+				t.Error("target does not meet expectation", err, target, newValue)
+			}
+			reset.Reset()
+			if target != originalValue {
+				t.Error("target does not meet expectation", target, originalValue)
+			}
+
+			chainer, err := mvt.TryChain(&target)
+			if err != nil {
+				t.Error("error occurred", err)
+			}
+			reset, err = chainer.Elem().TrySet(newValue)
+			if err != nil || target != newValue {
+				t.Error("target does not meet expectation", err, target, newValue)
+			}
+			reset.Reset()
+			if target != originalValue {
+				t.Error("target does not meet expectation", target, originalValue)
+			}
+		}
+	})
+	t.Run("失败时不留下修改", func(t *testing.T) {
+		var s testStruct
+		var p testPlugins
+		cases := []struct {
+			fn  func() (mvt.Resetter, error)
+			err error
+		}{
+			{func() (mvt.Resetter, error) { return mvt.Chain(&s).Elem().Field(2).Elem().TrySet("str") }, mvt.ErrIncompatibleTypeAssignment},
+			{func() (mvt.Resetter, error) { return mvt.Chain(&s).Elem().Field(3).MapValue(1).Index(0).TrySet(1) }, mvt.ErrTargetIsNotSliceOrArray},
+			{func() (mvt.Resetter, error) {
+				return mvt.Chain(&p).Elem().Field(1).MapValue("k").Elem().FieldByName("x").TrySet(1)
+			}, mvt.ErrStructFieldNotFound},
+			{func() (mvt.Resetter, error) {
+				return mvt.Chain(&p).Elem().Field(1).MapValue("k").Elem().Field(0).TrySetFuncOuts(nil)
+			}, mvt.ErrTargetIsNotFunc},
+		}
+		for i, v := range cases {
+			reset, err := v.fn()
+			if !errors.Is(err, v.err) || reset != nil {
+				t.Error("error does not meet expectation", i, err, v.err)
+			}
+		}
+		if s.unexportedField3 != nil || s.unexportedField4 != nil || p.m != nil {
+			t.Error("target was modified", s, p)
+		}
+		if len(mvt.Active()) != 0 {
+			t.Error("modification was not reset", mvt.Active())
+		}
+	})
+}
+
+func TestSet(t *testing.T) {
+	t.Run("Target 是 nil", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrTargetCannotBeNil) {
+				t.Error("no ErrTargetCannotBeNil panic occurred", recovered)
+			}
+		}()
+		mvt.Set[int8](nil, 1)
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		for range 100 {
+			originalValue := int8(rand.Intn(100))
+			target := originalValue
+			newValue := int8(rand.Intn(100))
+			reset := mvt.Set(&target, newValue)
+			if target != newValue {
+				t.Error("target does not meet expectation", target, newValue)
+			}
+			reset.Reset()
+			if target != originalValue {
+				t.Error("target does not meet expectation", target, originalValue)
+			}
+		}
+	})
+}
+
+func TestSetField(t *testing.T) {
+	t.Run("字段类型不一致", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrIncompatibleTypeAssignment) {
+				t.Error("no ErrIncompatibleTypeAssignment panic occurred", recovered)
+			}
+		}()
+		var target testStruct
+		mvt.SetField(&target, "unexportedField2", 1)
+	})
+
+	t.Run("字段不存在", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrStructFieldNotFound) {
+				t.Error("no ErrStructFieldNotFound panic occurred", recovered)
+			}
+		}()
+		var target testStruct
+		mvt.SetField(&target, "notExist", 1)
+	})
+
+	t.Run("Target 不是结构体", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrTargetIsNotStruct) {
+				t.Error("no ErrTargetIsNotStruct panic occurred", recovered)
+			}
+		}()
+		var target int
+		mvt.SetField(&target, "field", 1)
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		for range 100 {
+			originalValue := testImpl(rand.Intn(1000))
+			target := testStruct{unexportedField2: originalValue}
+			newValue := testImpl(rand.Intn(1000))
+			reset := mvt.SetField(&target, "unexportedField2", newValue)
+			if target.unexportedField2 != newValue {
+				t.Error("target does not meet expectation", target, newValue)
+			}
+			reset.Reset()
+			if target.unexportedField2 != originalValue {
+				t.Error("target does not meet expectation", target, originalValue)
+			}
+		}
+	})
+}
+
+func TestSetMapKey(t *testing.T) {
+	t.Run("Target 是 nil", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrTargetCannotBeNilType) {
+				t.Error("no ErrTargetCannotBeNilType panic occurred", recovered)
+			}
+		}()
+		mvt.SetMapKey(map[int]int(nil), 1, 1)
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		for range 100 {
+			key := rand.Intn(1000)
+			originalValue := rand.Intn(1000)
+			target := map[int]int{key: originalValue}
+			newValue := rand.Intn(1000)
+			reset := mvt.SetMapKey(target, key, newValue)
+			reset2 := mvt.SetMapKey(target, key+1, newValue)
+			if target[key] != newValue || target[key+1] != newValue {
+				t.Error("target does not meet expectation", target, newValue)
+			}
+			reset2.Reset()
+			reset.Reset()
+			if _, ok := target[key+1]; ok || target[key] != originalValue || len(target) != 1 {
+				t.Error("target does not meet expectation", target, originalValue)
+			}
+		}
+	})
+}
+
+func TestSetSliceElem(t *testing.T) {
+	t.Run("下标越界", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrIndexOutOfBound) {
+				t.Error("no ErrIndexOutOfBound panic occurred", recovered)
+			}
+		}()
+		mvt.SetSliceElem([]int{1}, -2, 1)
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		for range 100 {
+			originalValue := rand.Intn(1000)
+			target := []int{originalValue, originalValue}
+			newValue := rand.Intn(1000)
+			reset := mvt.SetSliceElem(target, -1, newValue)
+			if target[1] != newValue || target[0] != originalValue {
+				t.Error("target does not meet expectation", target, newValue)
+			}
+			reset.Reset()
+			if target[1] != originalValue {
+				t.Error("target does not meet expectation", target, originalValue)
+			}
+		}
+	})
+}
+
+func TestSetFunc(t *testing.T) {
+	t.Run("Target 不是函数", func(t *testing.T) {
+		defer func() {
+			recovered, _ := recover().(error)
+			if !errors.Is(recovered, mvt.ErrTargetIsNotFunc) {
+				t.Error("no ErrTargetIsNotFunc panic occurred", recovered)
+			}
+		}()
+		var target int
+		mvt.SetFunc(&target, 1)
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		fn := func() int { return 0 }
+		for range 100 {
+			newValue := rand.Intn(1000)
+			reset := mvt.SetFunc(&fn, func() int { return newValue })
+			if fn() != newValue {
+				t.Error("target does not meet expectation", fn(), newValue)
+			}
+			reset.Reset()
+			if fn() != 0 {
+				t.Error("target does not meet expectation", fn())
+			}
+		}
+	})
+}
+
+func TestGroup(t *testing.T) {
+	t.Run("正常运行", func(t *testing.T) {
+		for range 100 {
+			originalValue := rand.Intn(1000)
+			target := originalValue
+			s := testStruct{unexportedField2: testImpl(originalValue)}
+			arr := []int{originalValue}
+			m := map[int]int{}
+			fn := func() int { return originalValue }
+			var data *testStruct
+			newValue := rand.Intn(1000)
+
+			var g mvt.Group
+			g.Var(&target, newValue)
+			g.Var(&target, newValue+1)
+			g.FieldByName(&s, "unexportedField2", newValue)
+			g.Field(&s, 0, testImpl(newValue))
+			g.Elem(arr, 0, newValue)
+			g.Map(m, 1, newValue)
+			g.FuncOuts(&fn, []mvt.OutValue{{Values: []any{newValue}}})
+			g.Chain(&data).Elem().Elem().Field(1).Set(newValue)
+			g.Add(mvt.Set(&target, newValue+2))
+			if target != newValue+2 || int(s.unexportedField2) != newValue || s.unexportedField != testImpl(newValue) ||
+				arr[0] != newValue || m[1] != newValue || fn() != newValue || int(data.unexportedField2) != newValue {
+				t.Error("target value does not meet expectation")
+			}
+			g.Reset()
+			if target != originalValue || int(s.unexportedField2) != originalValue || s.unexportedField != nil ||
+				arr[0] != originalValue || len(m) != 0 || fn() != originalValue || data != nil {
+				t.Error("target value does not meet expectation", target, s, arr, m, data)
+			}
+			g.Reset()
+		}
+	})
+
+	t.Run("回退 panic", func(t *testing.T) {
+		for range 100 {
+			originalValue := rand.Intn(1000)
+			target := originalValue
+			target2 := originalValue
+			newValue := rand.Intn(1000)
+			err := errors.New("reset failed")
+
+			var g mvt.Group
+			g.Var(&target, newValue)
+			g.Add(panicResetter{err})
+			g.Var(&target2, newValue)
+			g.Add(panicResetter{"reset failed"})
+			func() {
+				defer func() {
+					recovered, _ := recover().(error)
+					if !errors.Is(recovered, err) || !errors.Is(recovered, mvt.ErrResetFailed) {
+						t.Error("panic does not meet expectation", recovered)
+					}
+				}()
+				g.Reset()
+			}()
+			if target != originalValue || target2 != originalValue {
+				t.Error("target value does not meet expectation", target, target2, originalValue)
+			}
+			if err := g.TryReset(); err != nil {
+				t.Error("error occurred", err)
+			}
+		}
+	})
+}
+
+func TestActive(t *testing.T) {
+	t.Run("记录未回退的修改", func(t *testing.T) {
+		for range 100 {
+			target := rand.Intn(1000)
+			count := len(mvt.Active())
+			reset := mvt.Var(&target, 0)
+			_, file, line, _ := runtime.Caller(0)
+			active := mvt.Active()
+			if len(active) != count+1 || active[count].Site != fmt.Sprintf("%s:%d", file, line-1) ||
+				!strings.Contains(active[count].Stack, "TestActive") {
+				t.Fatal("active does not meet expectation", active)
+			}
+			reset.Reset()
+			reset.Reset()
+			if len(mvt.Active()) != count {
+				t.Error("active does not meet expectation", mvt.Active())
+			}
+		}
+	})
+
+	t.Run("VerifyNone", func(t *testing.T) {
+		for range 100 {
+			target := rand.Intn(1000)
+			leaked := mvt.Var(&target, 0)
+			tb := &fakeTB{TB: t}
+			mvt.VerifyNone(tb)
+			mt := mvt.T(tb)
+			mt.Var(&target, 1)
+			reset := mvt.Var(&target, 2)
+			tb.cleanup()
+			if !strings.Contains(tb.errors, "1 modifications were not reset") || !strings.Contains(tb.errors, "mvt_test.go") {
+				t.Error("errors does not meet expectation", tb.errors)
+			}
+			reset.Reset()
+			leaked.Reset()
+
+			tb = &fakeTB{TB: t}
+			mvt.VerifyNone(tb)
+			mvt.T(tb).Var(&target, 1)
+			tb.cleanup()
+			if tb.errors != "" {
+				t.Error("errors does not meet expectation", tb.errors)
+			}
+		}
+	})
+}
+
+func TestDump(t *testing.T) {
+	t.Run("修改的描述", func(t *testing.T) {
+		for range 100 {
+			value := rand.Intn(1000)
+			target := value
+			data := &testStruct{}
+			m := map[string]int{"key": value}
+			reset := mvt.Var(&target, value+1)
+			reset2 := mvt.Chain(&data).Elem().Elem().FieldByName("unexportedField2").Set(value)
+			reset3 := mvt.MapDelete(m, "key")
+			active := mvt.Active()
+			active = active[len(active)-3:]
+			if active[0].Type != reflect.TypeOf(0) || active[0].Path != "int" || active[0].Old != fmt.Sprint(value) ||
+				active[0].New != fmt.Sprint(value+1) {
+				t.Error("override does not meet expectation", active[0])
+			}
+			if active[1].Path != "**modify_variables_temporarily_test.testStruct -> "+
+				"*modify_variables_temporarily_test.testStruct -> modify_variables_temporarily_test.testStruct -> "+
+				"modify_variables_temporarily_test.testImpl" {
+				t.Error("override does not meet expectation", active[1].Path)
+			}
+			if active[2].Path != "map[string]int -> int" || active[2].New != "<deleted>" {
+				t.Error("override does not meet expectation", active[2])
+			}
+			if fmt.Sprint(reset) != active[0].String() || !strings.Contains(active[0].String(), "mvt_test.go:") {
+				t.Error("string does not meet expectation", reset, active[0])
+			}
+			reset3.Reset()
+			reset2.Reset()
+			reset.Reset()
+		}
+	})
+
+	t.Run("创建时格式化", func(t *testing.T) {
+		for range 100 {
+			value := rand.Intn(1000)
+			original := map[string]int{"a": value}
+			substitute := map[string]int{"b": value}
+			target := original
+			reset := mvt.Var(&target, substitute)
+			original["a"]++
+			substitute["c"] = value
+			active := mvt.Active()
+			if o := active[len(active)-1]; o.Old != fmt.Sprintf("%#v", map[string]int{"a": value}) ||
+				o.New != fmt.Sprintf("%#v", map[string]int{"b": value}) {
+				t.Error("override does not meet expectation", o)
+			}
+			reset.Reset()
+		}
+	})
+
+	t.Run("Dump", func(t *testing.T) {
+		value := rand.Intn(1000)
+		fn := func() int { return 0 }
+		reset := mvt.FuncOuts(&fn, []mvt.OutValue{{Values: []any{value}}})
+		defer reset.Reset()
+		sb := &strings.Builder{}
+		mvt.Dump(sb)
+		lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+		if !strings.HasPrefix(lines[0], "SITE") || !strings.Contains(lines[len(lines)-1], "mvt_test.go:") ||
+			!strings.Contains(lines[len(lines)-1], "<stub with 1 outs>") {
+			t.Error("dump does not meet expectation", sb.String())
+		}
+		if !strings.Contains(fmt.Sprint(reset), "func() int") {
+			t.Error("string does not meet expectation", reset)
+		}
+	})
+}
+
+func TestChainError(t *testing.T) {
+	t.Run("字段不存在", func(t *testing.T) {
+		data := &testStruct{}
+		_, err := mvt.Chain(&data).Elem().Elem().FieldByName("notExist").TrySet(1)
+		var chainErr *mvt.ChainError
+		var fieldErr *mvt.FieldNotFoundError
+		if !errors.As(err, &chainErr) || !errors.As(err, &fieldErr) || !errors.Is(err, mvt.ErrStructFieldNotFound) {
+			t.Fatal("no ChainError returned", err)
+		}
+		if chainErr.StepIndex != 2 || len(chainErr.Path) != 3 || chainErr.Type != reflect.TypeOf(testStruct{}) ||
+			chainErr.Path[2] != (mvt.Step{Op: "FieldByName", Arg: "notExist"}) {
+			t.Error("chain error does not meet expectation", chainErr)
+		}
+		if fieldErr.Struct != reflect.TypeOf(testStruct{}) || fieldErr.Name != "notExist" || fieldErr.Index != -1 {
+			t.Error("field error does not meet expectation", fieldErr)
+		}
+		if !strings.Contains(err.Error(), `Chain.Elem().Elem() >>> .FieldByName("notExist")`) {
+			t.Error("error message does not meet expectation", err)
+		}
+		if _, err = mvt.TryField(data, 10, 1); !errors.As(err, &fieldErr) || fieldErr.Index != 10 || fieldErr.Name != "" {
+			t.Error("no FieldNotFoundError returned", err)
+		}
+	})
+
+	t.Run("下标越界", func(t *testing.T) {
+		for range 100 {
+			s := make([][]int, rand.Intn(5)+1)
+			index := len(s) + rand.Intn(5)
+			_, err := mvt.Chain(s).Index(index).Index(0).TrySet(1)
+			var chainErr *mvt.ChainError
+			var indexErr *mvt.IndexOutOfBoundError
+			if !errors.As(err, &chainErr) || !errors.As(err, &indexErr) || !errors.Is(err, mvt.ErrIndexOutOfBound) {
+				t.Fatal("no IndexOutOfBoundError returned", err)
+			}
+			if chainErr.StepIndex != 0 || indexErr.Index != index || indexErr.Len != len(s) || indexErr.Type != reflect.TypeOf(s) {
+				t.Error("error does not meet expectation", chainErr, indexErr)
+			}
+		}
+	})
+
+	t.Run("路径表达式", func(t *testing.T) {
+		s := &testStruct{unexportedField4: map[any]any{1: []int{1}}}
+		c, err := mvt.Path(s, ".unexportedField4[1]*[3]")
+		if err != nil {
+			t.Fatal("error occurred", err)
+		}
+		_, err = c.Get()
+		var chainErr *mvt.ChainError
+		if !errors.As(err, &chainErr) || !errors.Is(err, mvt.ErrIndexOutOfBound) {
+			t.Fatal("no ChainError returned", err)
+		}
+		expected := []mvt.Step{{Op: "FieldByName", Arg: "unexportedField4"}, {Op: "MapValue", Arg: 1}, {Op: "Elem"}, {Op: "Index", Arg: 3}}
+		if !reflect.DeepEqual(chainErr.Path, expected) || chainErr.StepIndex != 3 {
+			t.Error("chain error does not meet expectation", chainErr)
+		}
+	})
+
+	t.Run("最终变量类型不符", func(t *testing.T) {
+		data := &testStruct{}
+		_, err := mvt.Chain(&data).Elem().Elem().Field(1).TrySetFuncOuts(nil)
+		var chainErr *mvt.ChainError
+		if !errors.As(err, &chainErr) || !errors.Is(err, mvt.ErrTargetIsNotFunc) {
+			t.Fatal("no ChainError returned", err)
+		}
+		if chainErr.StepIndex != len(chainErr.Path) || chainErr.Type != reflect.TypeOf(testImpl(0)) {
+			t.Error("chain error does not meet expectation", chainErr)
+		}
+
+		_, err = mvt.Chain(&data).Elem().Elem().Field(1).TrySet("str")
+		if !errors.As(err, &chainErr) || !errors.Is(err, mvt.ErrIncompatibleTypeAssignment) {
+			t.Fatal("no ChainError returned", err)
+		}
+		if chainErr.StepIndex != len(chainErr.Path) || chainErr.Type != reflect.TypeOf(testImpl(0)) {
+			t.Error("chain error does not meet expectation", chainErr)
+		}
+	})
+}
+
+func TestSuggestion(t *testing.T) {
+	t.Run("字段拼写错误", func(t *testing.T) {
+		_, err := mvt.TryFieldByName(&testStruct{}, "UnexportedFeild2", 1)
+		var fieldErr *mvt.FieldNotFoundError
+		if !errors.As(err, &fieldErr) {
+			t.Fatal("no FieldNotFoundError returned", err)
+		}
+		if len(fieldErr.Fields) != 4 || fieldErr.Fields[0] != (mvt.FieldInfo{
+			Index: 1, Name: "unexportedField2", Type: reflect.TypeOf(testImpl(0)), Exported: false}) {
+			t.Error("fields does not meet expectation", fieldErr.Fields)
+		}
+		if !strings.Contains(err.Error(), "did you mean unexportedField2?") ||
+			!strings.Contains(err.Error(), "#3 unexportedField4 map[interface {}]interface {} (unexported)") {
+			t.Error("error message does not meet expectation", err)
+		}
+
+		_, err = mvt.TryFieldByName(&testStruct{}, "other", 1)
+		if !errors.As(err, &fieldErr) || strings.Contains(err.Error(), "did you mean") {
+			t.Error("error message does not meet expectation", err)
+		}
+	})
+
+	t.Run("映射键拼写错误", func(t *testing.T) {
+		m := map[string]int{"key": 1, "other": 2, "another": 3}
+		_, err := mvt.TryMapDelete(m, "kye")
+		var keyErr *mvt.MapKeyNotFoundError
+		if !errors.As(err, &keyErr) || !errors.Is(err, mvt.ErrMapKeyNotFound) {
+			t.Fatal("no MapKeyNotFoundError returned", err)
+		}
+		if keyErr.Key != "kye" || keyErr.Map != reflect.TypeOf(m) || len(keyErr.Keys) != 3 || keyErr.Keys[0] != "key" {
+			t.Error("map key error does not meet expectation", keyErr)
+		}
+		if !strings.Contains(err.Error(), `did you mean "key"?`) {
+			t.Error("error message does not meet expectation", err)
+		}
+
+		_, err = mvt.Chain(&m).Elem().TryDeleteKey("othr")
+		if !errors.As(err, &keyErr) || keyErr.Keys[0] != "other" {
+			t.Error("no MapKeyNotFoundError returned", err)
+		}
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("读取深层值", func(t *testing.T) {
+		for range 100 {
+			value := rand.Intn(1000)
+			impl := testImpl(value)
+			s := &testStruct{
+				unexportedField:  testImpl2(value),
+				unexportedField3: &impl,
+				unexportedField4: map[any]any{"key": []int{value}},
+			}
+			v, err := mvt.Chain(&s).Elem().Elem().FieldByName("unexportedField4").MapValue("key").Elem().Index(0).Get()
+			if err != nil || v != value {
+				t.Error("value does not meet expectation", v, err)
+			}
+			if v := mvt.Peek[testImpl](mvt.Chain(&s).Elem().Elem().Field(2).Elem()); v != impl {
+				t.Error("value does not meet expectation", v)
+			}
+			if v := mvt.Peek[testImpl2](mvt.Chain(s).Elem().Field(0).Elem()); v != testImpl2(value) {
+				t.Error("value does not meet expectation", v)
+			}
+			c, err := mvt.Path(s, ".unexportedField4[`key`]*[0]")
+			if err != nil {
+				t.Fatal("error occurred", err)
+			}
+			if v := mvt.Peek[int](c); v != value {
+				t.Error("value does not meet expectation", v)
+			}
+		}
+	})
+
+	t.Run("不修改 nil 值", func(t *testing.T) {
+		s := &testStruct{}
+		if _, err := mvt.Chain(s).Elem().FieldByName("unexportedField3").Elem().Get(); !errors.Is(err, mvt.ErrCannotToNext) {
+			t.Error("no ErrCannotToNext returned", err)
+		}
+		if _, err := mvt.Chain(s).Elem().Field(0).Elem().Get(); !errors.Is(err, mvt.ErrCannotToNext) {
+			t.Error("no ErrCannotToNext returned", err)
+		}
+		if _, err := mvt.Chain(s).Elem().Field(3).MapValue(1).Get(); !errors.Is(err, mvt.ErrNilValue) {
+			t.Error("no ErrNilValue returned", err)
+		}
+		var keyErr *mvt.MapKeyNotFoundError
+		if _, err := mvt.Chain(&testStruct{unexportedField4: map[any]any{}}).Elem().Field(3).MapValue(1).Get(); !errors.As(err, &keyErr) {
+			t.Error("no MapKeyNotFoundError returned", err)
+		}
+		if s.unexportedField != nil || s.unexportedField3 != nil || s.unexportedField4 != nil {
+			t.Error("target was modified", s)
+		}
+	})
+
+	t.Run("类型不符", func(t *testing.T) {
+		s := &testStruct{unexportedField2: 1}
+		defer func() {
+			if recovered, _ := recover().(error); !errors.Is(recovered, mvt.ErrIncompatibleTypeAssignment) {
+				t.Error("no ErrIncompatibleTypeAssignment panic occurred", recovered)
+			}
+		}()
+		mvt.Peek[int](mvt.Chain(s).Elem().Field(1))
+	})
+}
+
+func TestNoAutoAlloc(t *testing.T) {
+	t.Run("遇到 nil 值报错", func(t *testing.T) {
+		s := &testStruct{}
+		var chainErr *mvt.ChainError
+		_, err := mvt.Chain(s, mvt.NoAutoAlloc()).Elem().FieldByName("unexportedField3").Elem().TrySet(testImpl(1))
+		if !errors.Is(err, mvt.ErrNilValue) || !errors.As(err, &chainErr) || chainErr.StepIndex != 2 {
+			t.Error("no ErrNilValue returned", err)
+		}
+		_, err = mvt.Chain(s, mvt.NoAutoAlloc()).Elem().Field(0).Elem().TrySet(testImpl2(1))
+		if !errors.Is(err, mvt.ErrNilValue) || !errors.As(err, &chainErr) || chainErr.StepIndex != 2 {
+			t.Error("no ErrNilValue returned", err)
+		}
+		_, err = mvt.Chain(s, mvt.NoAutoAlloc()).Elem().Field(3).MapValue(1).TrySet(1)
+		if !errors.Is(err, mvt.ErrNilValue) || !errors.As(err, &chainErr) || chainErr.StepIndex != 2 {
+			t.Error("no ErrNilValue returned", err)
+		}
+		c, err := mvt.Path(s, ".unexportedField3*", mvt.NoAutoAlloc())
+		if err != nil {
+			t.Fatal("error occurred", err)
+		}
+		if _, err = c.TrySet(testImpl(1)); !errors.Is(err, mvt.ErrNilValue) {
+			t.Error("no ErrNilValue returned", err)
+		}
+		if s.unexportedField != nil || s.unexportedField3 != nil || s.unexportedField4 != nil {
+			t.Error("target was modified", s)
+		}
+	})
+
+	t.Run("路径中间的键不存在", func(t *testing.T) {
+		s := &testStruct{unexportedField4: map[any]any{}}
+		var keyErr *mvt.MapKeyNotFoundError
+		_, err := mvt.Chain(s, mvt.NoAutoAlloc()).Elem().Field(3).MapValue("key").Elem().TrySet(1)
+		if !errors.As(err, &keyErr) {
+			t.Error("no MapKeyNotFoundError returned", err)
+		}
+		if len(s.unexportedField4) != 0 {
+			t.Error("target was modified", s.unexportedField4)
+		}
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		for range 100 {
+			value := rand.Intn(1000)
+			impl := testImpl(value)
+			s := &testStruct{unexportedField3: &impl, unexportedField4: map[any]any{"key": []int{value}}}
+
+			r1 := mvt.Chain(s, mvt.NoAutoAlloc()).Elem().Field(2).Elem().Set(value + 1)
+			r2 := mvt.Chain(s, mvt.NoAutoAlloc()).Elem().Field(3).MapValue("key").Elem().Index(0).Set(value + 2)
+			r3 := mvt.Chain(s, mvt.NoAutoAlloc()).Elem().Field(3).MapValue("new").Set(value + 3)
+			r4 := mvt.Chain(s, mvt.NoAutoAlloc()).Elem().Field(0).Set(testImpl2(value + 4))
+			if impl != testImpl(value+1) || s.unexportedField4["key"].([]int)[0] != value+2 ||
+				s.unexportedField4["new"] != value+3 || s.unexportedField != testImpl2(value+4) {
+				t.Error("value does not meet expectation", s)
+			}
+			r4.Reset()
+			r3.Reset()
+			r2.Reset()
+			r1.Reset()
+			if _, ok := s.unexportedField4["new"]; impl != testImpl(value) || ok ||
+				s.unexportedField4["key"].([]int)[0] != value || s.unexportedField != nil {
+				t.Error("value does not meet expectation", s)
+			}
+		}
+	})
+}
+
+func TestEach(t *testing.T) {
+	newPlugins := func() *testPlugins {
+		handler := func(n int) int { return n }
+		return &testPlugins{
+			list: []testPlugin{{handler: handler}, {handler: handler}, {handler: handler}},
+			m:    map[string]*testPlugin{"a1": {}, "a2": {}, "b1": {}},
+			arr:  [3]int{1, 2, 3},
+		}
+	}
+
+	t.Run("修改所有元素", func(t *testing.T) {
+		for range 100 {
+			value := rand.Intn(1000)
+			p := newPlugins()
+			r1 := mvt.Chain(p).Elem().FieldByName("list").Each().FieldByName("enabled").Set(true)
+			r2 := mvt.Chain(p).Elem().FieldByName("arr").Each().Set(value)
+			for _, v := range p.list {
+				if !v.enabled {
+					t.Error("value does not meet expectation", p.list)
+				}
+			}
+			if p.arr != [3]int{value, value, value} {
+				t.Error("value does not meet expectation", p.arr)
+			}
+			r2.Reset()
+			r1.Reset()
+			for _, v := range p.list {
+				if v.enabled {
+					t.Error("value does not meet expectation", p.list)
+				}
+			}
+			if p.arr != [3]int{1, 2, 3} {
+				t.Error("value does not meet expectation", p.arr)
+			}
+			if len(mvt.Active()) != 0 {
+				t.Error("modification was not reset", mvt.Active())
+			}
+		}
+	})
+
+	t.Run("修改满足条件的元素", func(t *testing.T) {
+		p := newPlugins()
+		r := mvt.Chain(p).Elem().FieldByName("m").Where(func(key, _ any) bool {
+			return strings.HasPrefix(key.(string), "a")
+		}).Elem().FieldByName("enabled").Set(true)
+		if !p.m["a1"].enabled || !p.m["a2"].enabled || p.m["b1"].enabled {
+			t.Error("value does not meet expectation", p.m)
+		}
+		r.Reset()
+		if p.m["a1"].enabled || p.m["a2"].enabled || p.m["b1"].enabled {
+			t.Error("value does not meet expectation", p.m)
+		}
+
+		r = mvt.Chain(p).Elem().FieldByName("arr").Where(func(_, val any) bool { return val.(int) > 1 }).Set(0)
+		if p.arr != [3]int{1, 0, 0} {
+			t.Error("value does not meet expectation", p.arr)
+		}
+		r.Reset()
+		if p.arr != [3]int{1, 2, 3} {
+			t.Error("value does not meet expectation", p.arr)
+		}
+	})
+
+	t.Run("替换所有函数", func(t *testing.T) {
+		p := newPlugins()
+		var chainErr *mvt.ChainError
+		if _, err := mvt.Chain(p).Elem().FieldByName("list").Each().FieldByName("handler").
+			TrySetFuncOuts([]mvt.OutValue{{Values: []any{-1}}}); !errors.Is(err, mvt.ErrWildcardFuncOuts) ||
+			!errors.As(err, &chainErr) || chainErr.StepIndex != 2 {
+			t.Error("no ErrWildcardFuncOuts returned", err)
+		}
+		r := mvt.Chain(p).Elem().FieldByName("list").Each().FieldByName("handler").
+			SetEachFuncOuts([]mvt.OutValue{{Values: []any{-1}}}, mvt.ExpectCalls(1))
+		if err := r.Check(); !errors.Is(err, mvt.ErrExpectationNotMet) {
+			t.Error("no ErrExpectationNotMet returned", err)
+		}
+		for i, v := range p.list {
+			if n := v.handler(i); n != -1 {
+				t.Error("value does not meet expectation", n)
+			}
+		}
+		elems := r.Elems()
+		if len(elems) != 3 {
+			t.Error("elems does not meet expectation", elems)
+		}
+		for i, v := range elems {
+			if v.CallCount() != 1 || v.LastArgs()[0] != i {
+				t.Error("calls does not meet expectation", v.Calls())
+			}
+			if n := v.Original().(func(int) int)(i); n != i {
+				t.Error("original does not meet expectation", n)
+			}
+		}
+		if err := r.Check(); err != nil {
+			t.Error("unexpected error", err)
+		}
+		r.Reset()
+		for _, v := range p.list {
+			if n := v.handler(1); n != 1 {
+				t.Error("value does not meet expectation", n)
+			}
+		}
+	})
+
+	t.Run("读取与路径表达式", func(t *testing.T) {
+		p := newPlugins()
+		values, err := mvt.Chain(p).Elem().FieldByName("arr").Each().Get()
+		if err != nil || !reflect.DeepEqual(values, []any{1, 2, 3}) {
+			t.Error("value does not meet expectation", values, err)
+		}
+		c, err := mvt.Path(p, ".list[*].enabled")
+		if err != nil {
+			t.Fatal("error occurred", err)
+		}
+		r := c.Set(true)
+		if values := mvt.Peek[[]any](c); !reflect.DeepEqual(values, []any{true, true, true}) {
+			t.Error("value does not meet expectation", values)
+		}
+		r.Reset()
+		if values := mvt.Peek[[]any](c); !reflect.DeepEqual(values, []any{false, false, false}) {
+			t.Error("value does not meet expectation", values)
+		}
+	})
+
+	t.Run("修改失败时全部回退", func(t *testing.T) {
+		s := &testStruct{unexportedField4: map[any]any{"a": 1, "b": "b", "c": 3}}
+		if _, err := mvt.Chain(s).Elem().Field(3).Each().Elem().TrySet(2.5); !errors.Is(err, mvt.ErrIncompatibleTypeAssignment) {
+			t.Error("no ErrIncompatibleTypeAssignment returned", err)
+		}
+		if !reflect.DeepEqual(s.unexportedField4, map[any]any{"a": 1, "b": "b", "c": 3}) {
+			t.Error("target was modified", s.unexportedField4)
+		}
+		var chainErr *mvt.ChainError
+		if _, err := mvt.Chain(s).Elem().Each().TrySet(1); !errors.Is(err, mvt.ErrTargetIsNotIterable) ||
+			!errors.As(err, &chainErr) || chainErr.StepIndex != 1 {
+			t.Error("no ErrTargetIsNotIterable returned", err)
+		}
+		if _, err := mvt.Chain(s).Elem().Field(3).Where(nil).TrySet(1); !errors.Is(err, mvt.ErrPredicateCannotBeNil) {
+			t.Error("no ErrPredicateCannotBeNil returned", err)
+		}
+		if !reflect.DeepEqual(s.unexportedField4, map[any]any{"a": 1, "b": "b", "c": 3}) {
+			t.Error("target was modified", s.unexportedField4)
+		}
+	})
+
+	t.Run("初始化通配步骤之前的路径", func(t *testing.T) {
+		for range 100 {
+			value := rand.Intn(1000)
+			var p *testPlugins
+			if _, err := mvt.Chain(&p).Elem().Elem().FieldByName("arr").Each().Get(); !errors.Is(err, mvt.ErrNilValue) {
+				t.Error("no ErrNilValue returned", err)
+			}
+			r := mvt.Chain(&p).Elem().Elem().FieldByName("arr").Each().Set(value)
+			if p == nil || p.arr != [3]int{value, value, value} {
+				t.Error("value does not meet expectation", p)
+			}
+			r.Reset()
+			if p != nil {
+				t.Error("value does not meet expectation", p)
+			}
+			if _, err := mvt.Chain(&p, mvt.NoAutoAlloc()).Elem().Elem().FieldByName("arr").Each().TrySet(value); !errors.Is(err, mvt.ErrNilValue) {
+				t.Error("no ErrNilValue returned", err)
+			}
+			if _, err := mvt.Chain(&p).Elem().Elem().FieldByName("arr").Each().Each().TrySet(value); !errors.Is(err, mvt.ErrTargetIsNotIterable) {
+				t.Error("no ErrTargetIsNotIterable returned", err)
+			}
+			if p != nil {
+				t.Error("value does not meet expectation", p)
+			}
+		}
+	})
+}
+
+func TestPath(t *testing.T) {
+	t.Run("解析错误", func(t *testing.T) {
+		var target *testStruct
+		cases := []struct {
+			expr   string
+			column int
+		}{
+			{"a", 1},
+			{".", 2},
+			{".1a", 2},
+			{".#", 3},
+			{".#a", 3},
+			{"[", 2},
+			{"[1", 3},
+			{"[x]", 2},
+			{`["a]`, 2},
+			{`*.m["a"]x`, 9},
+			{`*.字段[1]?`, 8},
+		}
+		for _, v := range cases {
+			_, err := mvt.Path(&target, v.expr)
+			var pathErr *mvt.PathError
+			if !errors.Is(err, mvt.ErrInvalidPath) || !errors.As(err, &pathErr) || pathErr.Column != v.column {
+				t.Error("error does not meet expectation", v.expr, err)
+			}
+		}
+		if _, err := mvt.Path(nil, "*"); !errors.Is(err, mvt.ErrTargetCannotBeNil) {
+			t.Error("no ErrTargetCannotBeNil returned", err)
+		}
+	})
+
+	t.Run("正常运行", func(t *testing.T) {
+		type st struct {
+			m map[any][1]struct {
+				field string
+			}
+			s []int
+			p *st
+		}
+		for range 100 {
+			var data *st
+			key := rand.Intn(1000)
+			value := fmt.Sprint(rand.Intn(1000))
+			chainer, err := mvt.Path(&data, fmt.Sprintf("*.m[%d][0].field", key))
+			if err != nil {
+				t.Fatal("error occurred", err)
+			}
+			reset := chainer.Set(value)
+			if data.m[key][0].field != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset.Reset()
+			if data != nil {
+				t.Error("target value does not meet expectation", data)
+			}
+
+			data = &st{s: []int{1, 2}}
+			chainer, err = mvt.Path(&data, `.p.p.#0["key"][-1].#-1`)
+			if err != nil {
+				t.Fatal("error occurred", err)
+			}
+			reset = chainer.Set(value)
+			if data.p.p.m["key"][0].field != value {
+				t.Error("target value does not meet expectation", data, value)
+			}
+			reset.Reset()
+			if data.p != nil {
+				t.Error("target value does not meet expectation", data)
+			}
+
+			chainer, err = mvt.Path(&data, ".s[1]")
+			if err != nil {
+				t.Fatal("error occurred", err)
+			}
+			reset = chainer.Set(key)
+			if data.s[1] != key {
+				t.Error("target value does not meet expectation", data, key)
+			}
+			reset.Reset()
+			if data.s[1] != 2 {
+				t.Error("target value does not meet expectation", data)
+			}
+		}
+	})
+
+	t.Run("映射键字面量", func(t *testing.T) {
+		for range 100 {
+			m := map[any]int{}
+			value := rand.Intn(1000)
+			for _, v := range []struct {
+				expr string
+				key  any
+			}{
+				{`["a\"b"]`, "a\"b"},
+				{"[`a\\b`]", `a\b`},
+				{"[-1]", -1},
+				{"[true]", true},
+				{"[false]", false},
+			} {
+				chainer, err := mvt.Path(m, v.expr)
+				if err != nil {
+					t.Fatal("error occurred", err)
+				}
+				reset := chainer.Set(value)
+				if m[v.key] != value {
+					t.Error("target value does not meet expectation", m, v.key)
+				}
+				reset.Reset()
+				if len(m) != 0 {
+					t.Error("target value does not meet expectation", m)
+				}
+			}
+		}
+	})
+
+	t.Run("映射键不能转换成字符", func(t *testing.T) {
+		m := map[string]int{"1": 5}
+		chainer, err := mvt.Path(m, "[1]")
+		if err != nil {
+			t.Fatal("error occurred", err)
+		}
+		var chainErr *mvt.ChainError
+		if _, err = chainer.TrySet(7); !errors.Is(err, mvt.ErrInvalidMapKeyType) || !errors.As(err, &chainErr) {
+			t.Error("no ErrInvalidMapKeyType returned", err)
+		}
+		if len(m) != 1 || m["1"] != 5 {
+			t.Error("target was modified", m)
+		}
+
+		m2 := map[int8]int{}
+		chainer, err = mvt.Path(m2, "[300]")
+		if err != nil {
+			t.Fatal("error occurred", err)
+		}
+		if _, err = chainer.TrySet(7); !errors.Is(err, mvt.ErrInvalidMapKeyType) || len(m2) != 0 {
+			t.Error("no ErrInvalidMapKeyType returned", err, m2)
+		}
+	})
+
+	t.Run("类型不匹配", func(t *testing.T) {
+		var target []int
+		chainer, err := mvt.Path(&target, `["a"]`)
+		if err != nil {
+			t.Fatal("error occurred", err)
+		}
+		if _, err = chainer.TrySet(1); !errors.Is(err, mvt.ErrTargetIsNotMap) {
+			t.Error("no ErrTargetIsNotMap returned", err)
 		}
 	})
 }
@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Path 根据路径表达式路由到要修改的变量，等同于 Chain 的链式调用。
+// target 必须是可寻址的变量，不能是 nil。target 是指针时，路径从它指向的变量开始。
+// expr 路径表达式，由以下步骤组成：
+//
+//	.name    获取结构体字段，同 FieldByName。当前变量是指针时自动解引用。
+//	.#3      获取结构体序号是 3 的字段，同 Field，序号可以是负数。当前变量是指针时自动解引用。
+//	[0]      获取数组或切片的元素，同 Index。当前变量是映射时，获取整数键的值。
+//	["key"]  获取映射的键值，同 MapValue。键可以是字符串、整数、布尔字面量，转换成映射键的类型时不能改变值，如整数不能作为字符串键。
+//	[*]      获取数组、切片或映射的所有元素，同 Each。
+//	*        当前变量是指针或接口类型，获取它的内部类型变量，同 Elem。
+//
+// 例如 *.m[1][0].field 等同于 Chain(&Data).Elem().Elem().FieldByName("m").MapValue(1).Index(0).FieldByName("field")。
+// opts 同 Chain。
+func Path(target any, expr string, opts ...ChainOption) (ChainSetter, error) {
+	return path(target, expr, opts)
+}
+
+func path(target any, expr string, opts []ChainOption) (*chainSetter, error) {
+	actions, err := parsePath(expr)
+	if err != nil {
+		return nil, err
+	}
+	chainer, err := TryChain(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := chainer.(*chainSetter)
+	if c.value.Kind() == reflect.Pointer {
+		actions = append([]*action{implicitElem}, actions...)
+	}
+	c.actions = actions
+	return c, nil
+}
+
+// implicitElem Path 的目标是指针时添加的解引用，不出现在错误的步骤中。
+var implicitElem = &action{typ: toElem}
+
+// pathParser 路径表达式解析器。
+type pathParser struct {
+	expr string
+	pos  int
+}
+
+func parsePath(expr string) ([]*action, error) {
+	p := &pathParser{expr: expr}
+	actions := make([]*action, 0, strings.Count(expr, ".")+strings.Count(expr, "[")+strings.Count(expr, "*"))
+	for p.pos < len(p.expr) {
+		switch p.expr[p.pos] {
+		case '*':
+			p.pos++
+			actions = append(actions, &action{typ: toElem})
+		case '.':
+			p.pos++
+			actions = append(actions, &action{typ: toAutoElem})
+			if p.pos < len(p.expr) && p.expr[p.pos] == '#' {
+				p.pos++
+				index, err := p.parseInt()
+				if err != nil {
+					return nil, err
+				}
+				actions = append(actions, &action{toStructField, []any{index}})
+				continue
+			}
+			name, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, &action{toStructFieldByName, []any{name}})
+		case '[':
+			p.pos++
+			if strings.HasPrefix(p.expr[p.pos:], "*]") {
+				p.pos += len("*]")
+				actions = append(actions, &action{typ: toEach})
+				continue
+			}
+			key, err := p.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+			if p.pos >= len(p.expr) || p.expr[p.pos] != ']' {
+				return nil, p.errorf("expect ']'")
+			}
+			p.pos++
+			actions = append(actions, &action{toMapValueOrSeqElem, []any{key}})
+		default:
+			return nil, p.errorf("unexpected character %q", p.current())
+		}
+	}
+	return actions, nil
+}
+
+func (p *pathParser) parseIdent() (string, error) {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		if !(r == '_' || unicode.IsLetter(r) || (p.pos > start && unicode.IsDigit(r))) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return "", p.errorf("expect field name")
+	}
+	return p.expr[start:p.pos], nil
+}
+
+func (p *pathParser) parseInt() (int, error) {
+	start := p.pos
+	if p.pos < len(p.expr) && p.expr[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.expr) && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+		p.pos++
+	}
+	n, err := strconv.Atoi(p.expr[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("expect integer")
+	}
+	return n, nil
+}
+
+func (p *pathParser) parseLiteral() (any, error) {
+	if p.pos >= len(p.expr) {
+		return nil, p.errorf("expect map key or index")
+	}
+	switch c := p.expr[p.pos]; {
+	case c == '"' || c == '`':
+		start := p.pos
+		end := p.pos + 1
+		for end < len(p.expr) && p.expr[end] != c {
+			if c == '"' && p.expr[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.expr) {
+			return nil, p.errorf("unterminated string")
+		}
+		s, err := strconv.Unquote(p.expr[start : end+1])
+		if err != nil {
+			return nil, p.errorf("invalid string %s", p.expr[start:end+1])
+		}
+		p.pos = end + 1
+		return s, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseInt()
+	case strings.HasPrefix(p.expr[p.pos:], "true"):
+		p.pos += len("true")
+		return true, nil
+	case strings.HasPrefix(p.expr[p.pos:], "false"):
+		p.pos += len("false")
+		return false, nil
+	default:
+		return nil, p.errorf("expect map key or index")
+	}
+}
+
+func (p *pathParser) current() rune {
+	r, _ := utf8.DecodeRuneInString(p.expr[p.pos:])
+	return r
+}
+
+func (p *pathParser) errorf(format string, args ...any) error {
+	return newPathError(p.expr, utf8.RuneCountInString(p.expr[:p.pos])+1, format, args...)
+}
@@ -12,7 +12,13 @@
 
 package modify_variables_temporarily
 
-import "sync"
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
 
 type Resetter interface {
 	// Reset 回退变量值的修改。
@@ -22,8 +28,98 @@ type Resetter interface {
 type resetter struct {
 	once sync.Once
 	fn   func()
+	id   uint64
+	pcs  []uintptr // 创建修改时的调用栈。
+	modification
 }
 
-func newResetter(fn func()) Resetter { return &resetter{fn: fn} }
+// modification 修改的描述。
+type modification struct {
+	typ  reflect.Type // 被修改的变量的类型。
+	path string       // 到达被修改的变量经过的类型，形如 *main.Data -> main.Data -> int。
+	old  string       // 原值，创建修改时以 %#v 格式化，之后原值被原地修改也不影响它。
+	new  string       // 新值，格式同 old。
+}
+
+// label 以原样输出的描述，用于没有具体值的修改。
+type label string
+
+func (l label) GoString() string { return string(l) }
+
+// describeFuncOuts 生成替换函数返回值的描述。
+func describeFuncOuts(typ reflect.Type, path string, old any, outs []OutValue) modification {
+	return describe(typ, path, old, label(fmt.Sprintf("<stub with %d outs>", len(outs))))
+}
 
-func (r *resetter) Reset() { r.once.Do(r.fn) }
+// describe 生成修改的描述，typ 是 nil 时使用 old 的类型。须在修改生效前调用，此时格式化的 old 才是原值。
+func describe(typ reflect.Type, path string, old, new any) modification {
+	if typ == nil {
+		typ = reflect.TypeOf(old)
+	}
+	if path == "" && typ != nil {
+		path = typ.String()
+	}
+	return modification{typ: typ, path: path, old: fmt.Sprintf("%#v", old), new: fmt.Sprintf("%#v", new)}
+}
+
+var (
+	activeMu sync.Mutex
+	// active 未回退的修改。
+	active   = make(map[*resetter]struct{})
+	activeID uint64
+	// pkgPrefix 本包函数名的前缀，用于找到创建修改的位置。
+	pkgPrefix = reflect.TypeFor[resetter]().PkgPath() + "."
+)
+
+func newResetter(m modification, fn func()) Resetter {
+	pcs := make([]uintptr, 64)
+	r := &resetter{fn: fn, pcs: pcs[:runtime.Callers(2, pcs)], modification: m}
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	activeID++
+	r.id = activeID
+	active[r] = struct{}{}
+	return r
+}
+
+func (r *resetter) Reset() {
+	r.once.Do(func() {
+		defer func() {
+			activeMu.Lock()
+			defer activeMu.Unlock()
+			delete(active, r)
+		}()
+		r.fn()
+	})
+}
+
+func (r *resetter) String() string {
+	return fmt.Sprintf("%s: %s %s -> %s", r.site(), r.path, r.old, r.new)
+}
+
+// site 创建修改的位置，即调用栈中第一个不属于本包的函数。
+func (r *resetter) site() string {
+	frames := runtime.CallersFrames(r.pcs)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPrefix) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// stack 创建修改时的调用栈。
+func (r *resetter) stack() string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(r.pcs)
+	for {
+		frame, more := frames.Next()
+		_, _ = fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			return sb.String()
+		}
+	}
+}
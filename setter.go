@@ -17,6 +17,19 @@ type Setter interface {
 	// Set 替换当前变量的值。
 	Set(substitute any) Resetter
 
-	// SetFuncOuts 当前变量类型是函数，替换函数的返回值。
-	SetFuncOuts(outs []OutValue) Resetter
+	// SetFuncOuts 当前变量类型是函数，替换函数的返回值。返回的 FuncResetter 记录了函数的调用。
+	// 链中有 Each 或 Where 时失败，应使用 SetEachFuncOuts。
+	SetFuncOuts(outs []OutValue, opts ...FuncOption) FuncResetter
+
+	// TrySet 同 Set，但以返回值代替 panic 报告错误。
+	TrySet(substitute any) (Resetter, error)
+
+	// TrySetFuncOuts 同 SetFuncOuts，但以返回值代替 panic 报告错误。
+	TrySetFuncOuts(outs []OutValue, opts ...FuncOption) (FuncResetter, error)
+
+	// SetEachFuncOuts 同 SetFuncOuts，用于有 Each 或 Where 的链，替换每个元素的函数，各元素分别记录调用。
+	SetEachFuncOuts(outs []OutValue, opts ...FuncOption) EachFuncResetter
+
+	// TrySetEachFuncOuts 同 SetEachFuncOuts，但以返回值代替 panic 报告错误。
+	TrySetEachFuncOuts(outs []OutValue, opts ...FuncOption) (EachFuncResetter, error)
 }
@@ -0,0 +1,191 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import (
+	"bytes"
+	"reflect"
+	"unsafe"
+)
+
+// Snapshot 深度保存变量及其通过指针、切片、映射、接口可以到达的所有值，回退时原地写回，已有的指针仍然有效。
+// target 要保存的变量的指针，不能是 nil。
+// 循环引用只保存一次，函数、通道和 unsafe.Pointer 仅保存其本身。回退时只写回改变了的值。
+func Snapshot(target any) Resetter {
+	if target == nil {
+		panic(ErrTargetCannotBeNil)
+	}
+	ptrValue := reflect.ValueOf(target)
+	if ptrValue.Kind() != reflect.Pointer {
+		panic(ErrTargetIsNotPointer)
+	}
+	if ptrValue.IsNil() {
+		panic(ErrTargetCannotBeNilType)
+	}
+	s := &snapshot{visited: make(map[snapshotKey]struct{})}
+	s.savePointer(ptrValue.Type().Elem(), ptrValue.UnsafePointer())
+	m := describe(ptrValue.Type().Elem(), "", ptrValue.Elem().Interface(), label("<snapshot>"))
+	return newResetter(m, s.restore)
+}
+
+// snapshotKey 已保存的内存，切片以 len 区分不同长度的视图。
+type snapshotKey struct {
+	ptr unsafe.Pointer
+	typ reflect.Type
+	len int
+}
+
+// snapshot 保存的值。
+type snapshot struct {
+	visited map[snapshotKey]struct{}
+	// restoreFuncs 写回保存的值。
+	restoreFuncs []func()
+}
+
+func (s *snapshot) restore() {
+	for _, fn := range s.restoreFuncs {
+		fn()
+	}
+}
+
+// savePointer 保存 ptr 指向的 typ 类型的值，并继续保存它引用的值。
+func (s *snapshot) savePointer(typ reflect.Type, ptr unsafe.Pointer) {
+	key := snapshotKey{ptr: ptr, typ: typ}
+	if _, ok := s.visited[key]; ok || typ.Size() == 0 {
+		return
+	}
+	s.visited[key] = struct{}{}
+	value := reflect.NewAt(typ, ptr).Elem()
+	saved := reflect.New(typ).Elem()
+	saved.Set(value)
+	s.restoreFuncs = append(s.restoreFuncs, func() {
+		if changed(ptr, saved.Addr().UnsafePointer(), typ.Size()) {
+			value.Set(saved)
+		}
+	})
+	s.walk(typ, ptr)
+}
+
+// walk 保存 ptr 处 typ 类型的值所引用的值，值本身已由调用者保存。
+func (s *snapshot) walk(typ reflect.Type, ptr unsafe.Pointer) {
+	switch typ.Kind() {
+	case reflect.Pointer:
+		if elemPtr := *(*unsafe.Pointer)(ptr); elemPtr != nil {
+			s.savePointer(typ.Elem(), elemPtr)
+		}
+	case reflect.Struct:
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			s.walk(field.Type, unsafe.Add(ptr, field.Offset))
+		}
+	case reflect.Array:
+		for i := range typ.Len() {
+			s.walk(typ.Elem(), unsafe.Add(ptr, uintptr(i)*typ.Elem().Size()))
+		}
+	case reflect.Slice:
+		s.saveSlice(reflect.NewAt(typ, ptr).Elem())
+	case reflect.Map:
+		s.saveMap(reflect.NewAt(typ, ptr).Elem())
+	case reflect.Interface:
+		if value := reflect.NewAt(typ, ptr).Elem(); !value.IsNil() {
+			s.walkCopy(value.Elem())
+		}
+	}
+}
+
+// saveSlice 保存切片 len 范围内的元素。
+func (s *snapshot) saveSlice(value reflect.Value) {
+	elemType := value.Type().Elem()
+	key := snapshotKey{ptr: value.UnsafePointer(), typ: elemType, len: value.Len()}
+	if _, ok := s.visited[key]; ok || value.Len() <= 0 || elemType.Size() == 0 {
+		return
+	}
+	s.visited[key] = struct{}{}
+	saved := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+	reflect.Copy(saved, value)
+	s.restoreFuncs = append(s.restoreFuncs, func() {
+		if changed(value.UnsafePointer(), saved.UnsafePointer(), uintptr(value.Len())*elemType.Size()) {
+			reflect.Copy(value, saved)
+		}
+	})
+	for i := range value.Len() {
+		s.walk(elemType, value.Index(i).Addr().UnsafePointer())
+	}
+}
+
+// saveMap 保存映射的所有键值，回退时若键值有变化，清空映射后重新写入。
+func (s *snapshot) saveMap(value reflect.Value) {
+	if value.IsNil() {
+		return
+	}
+	key := snapshotKey{ptr: value.UnsafePointer(), typ: value.Type()}
+	if _, ok := s.visited[key]; ok {
+		return
+	}
+	s.visited[key] = struct{}{}
+	var keys, values []reflect.Value
+	for iter := value.MapRange(); iter.Next(); {
+		keys = append(keys, iter.Key())
+		values = append(values, iter.Value())
+	}
+	s.restoreFuncs = append(s.restoreFuncs, func() {
+		if !mapChanged(value, keys, values) {
+			return
+		}
+		value.Clear()
+		for i, k := range keys {
+			value.SetMapIndex(k, values[i])
+		}
+	})
+	for i, k := range keys {
+		s.walkCopy(k)
+		s.walkCopy(values[i])
+	}
+}
+
+// walkCopy 保存不可寻址的值所引用的值，如映射的键值和接口的动态值。
+func (s *snapshot) walkCopy(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Struct, reflect.Array, reflect.Slice, reflect.Map, reflect.Interface:
+		tmp := reflect.New(value.Type())
+		tmp.Elem().Set(value)
+		s.walk(value.Type(), tmp.UnsafePointer())
+	}
+}
+
+// mapChanged 比较映射当前的键值与保存的是否不同，值按内存比较，同 changed。
+func mapChanged(value reflect.Value, keys, values []reflect.Value) bool {
+	if value.Len() != len(keys) {
+		return true
+	}
+	typ := value.Type().Elem()
+	current, saved := reflect.New(typ), reflect.New(typ)
+	for i, k := range keys {
+		v := value.MapIndex(k)
+		if !v.IsValid() {
+			return true
+		}
+		current.Elem().Set(v)
+		saved.Elem().Set(values[i])
+		if changed(current.UnsafePointer(), saved.UnsafePointer(), typ.Size()) {
+			return true
+		}
+	}
+	return false
+}
+
+// changed 比较 ptr 处与保存的 size 字节内存是否不同。
+// 遍历可能到达只读内存，如接口中 reflect.Type 指向的类型描述符，写入会使进程崩溃，所以只写回改变了的内存。
+func changed(ptr, saved unsafe.Pointer, size uintptr) bool {
+	return !bytes.Equal(unsafe.Slice((*byte)(ptr), size), unsafe.Slice((*byte)(saved), size))
+}
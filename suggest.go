@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuggestedKeys 错误中列出的映射键的最大数量。
+const maxSuggestedKeys = 5
+
+// structFields 获取结构体的所有字段，name 不是空串时按与 name 的编辑距离排序。
+func structFields(structType reflect.Type, name string) []FieldInfo {
+	fields := make([]FieldInfo, structType.NumField())
+	for i := range fields {
+		field := structType.Field(i)
+		fields[i] = FieldInfo{Index: i, Name: field.Name, Type: field.Type, Exported: field.IsExported()}
+	}
+	if name != "" {
+		slices.SortStableFunc(fields, func(a, b FieldInfo) int {
+			return editDistance(name, a.Name) - editDistance(name, b.Name)
+		})
+	}
+	return fields
+}
+
+// nearestMapKeys 获取映射中与 key 编辑距离最近的若干个键。
+func nearestMapKeys(mapValue, keyValue reflect.Value) []any {
+	if mapValue.Len() <= 0 {
+		return nil
+	}
+	target := fmt.Sprint(keyValue.Interface())
+	type candidate struct {
+		key      any
+		distance int
+	}
+	candidates := make([]candidate, 0, mapValue.Len())
+	for iter := mapValue.MapRange(); iter.Next(); {
+		key := iter.Key().Interface()
+		candidates = append(candidates, candidate{key, editDistance(target, fmt.Sprint(key))})
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		if a.distance != b.distance {
+			return a.distance - b.distance
+		}
+		return strings.Compare(fmt.Sprint(a.key), fmt.Sprint(b.key))
+	})
+	keys := make([]any, 0, maxSuggestedKeys)
+	for _, v := range candidates[:min(len(candidates), maxSuggestedKeys)] {
+		keys = append(keys, v.key)
+	}
+	return keys
+}
+
+// isSimilar s 与 t 是否足够接近，可作为拼写错误的建议。
+func isSimilar(s, t string) bool {
+	return editDistance(s, t) <= max(1, utf8.RuneCountInString(s)/3)
+}
+
+// editDistance 忽略大小写的编辑距离，相邻字符交换计为一次编辑。
+func editDistance(s, t string) int {
+	a, b := []rune(strings.ToLower(s)), []rune(strings.ToLower(t))
+	prevPrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(b)]
+}
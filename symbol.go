@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// dwAttrGoRuntimeType Go 扩展的 DWARF 属性，值是类型描述符相对 runtime.types 的偏移。
+const dwAttrGoRuntimeType dwarf.Attr = 0x2904
+
+// symbolTable 运行中程序的符号表。
+type symbolTable struct {
+	variables map[string]elf.Symbol
+	varTypes  map[string]dwarf.Offset
+	dwarf     *dwarf.Data
+	types     uint64 // runtime.types 的地址。
+	slide     uint64 // 加载地址与链接地址的差，非 PIE 时是 0。
+	err       error
+}
+
+// Symbol 根据符号名查找包级变量，返回变量的指针和变量的类型，可用于 Chain、Var 等。
+// name 变量的完整符号名，如 "net/http.defaultClientTimeout"。
+// 依赖可执行文件中的 ELF 符号表和 DWARF 信息。go test 默认会去除它们，需以 -ldflags='-s=false -w=false' 运行。
+// 变量未被使用而被链接器去除，或符号信息不可用时，返回 ErrSymbolNotFound。
+func Symbol(name string) (any, reflect.Type, error) {
+	table := loadSymbolTable()
+	symbol, err := table.lookup(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	typ, err := table.typeOf(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reflect.NewAt(typ, table.address(symbol)).Interface(), typ, nil
+}
+
+// SymbolOf 同 Symbol，但变量类型由调用者指定，仅依赖 ELF 符号表。
+// 变量大小与 T 不一致时返回 ErrIncompatibleTypeAssignment。
+func SymbolOf[T any](name string) (*T, error) {
+	table := loadSymbolTable()
+	symbol, err := table.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	typ := reflect.TypeFor[T]()
+	if symbol.Size != uint64(typ.Size()) {
+		return nil, newIncompatibleTypeAssignmentError(name, typ.String())
+	}
+	return (*T)(table.address(symbol)), nil
+}
+
+func (t *symbolTable) lookup(name string) (elf.Symbol, error) {
+	if t.err != nil {
+		return elf.Symbol{}, newSymbolNotFoundError(name, t.err.Error())
+	}
+	symbol, ok := t.variables[name]
+	if !ok {
+		return elf.Symbol{}, newSymbolNotFoundError(name, "is not a variable in the symbol table, it may have been removed by the linker")
+	}
+	return symbol, nil
+}
+
+func (t *symbolTable) typeOf(name string) (reflect.Type, error) {
+	if t.dwarf == nil || t.types == 0 {
+		return nil, newSymbolNotFoundError(name, "has no type information, DWARF is stripped, use SymbolOf instead")
+	}
+	offset, ok := t.varTypes[name]
+	if !ok {
+		return nil, newSymbolNotFoundError(name, "has no type information in DWARF")
+	}
+	// 具名类型是 typedef，沿着它找到带有运行时类型的类型条目。
+	reader := t.dwarf.Reader()
+	var typeOffset uint64
+	for {
+		reader.Seek(offset)
+		entry, err := reader.Next()
+		if err != nil || entry == nil {
+			return nil, newSymbolNotFoundError(name, "has invalid type information in DWARF")
+		}
+		if typeOffset, ok = entry.Val(dwAttrGoRuntimeType).(uint64); ok {
+			break
+		}
+		if entry.Tag != dwarf.TagTypedef {
+			return nil, newSymbolNotFoundError(name, "has no runtime type in DWARF")
+		}
+		if offset, ok = entry.Val(dwarf.AttrType).(dwarf.Offset); !ok {
+			return nil, newSymbolNotFoundError(name, "has invalid type information in DWARF")
+		}
+	}
+	addr := uintptr(t.types + typeOffset + t.slide)
+	var i any
+	(*[2]unsafe.Pointer)(unsafe.Pointer(&i))[0] = *(*unsafe.Pointer)(unsafe.Pointer(&addr))
+	return reflect.TypeOf(i), nil
+}
+
+func (t *symbolTable) address(symbol elf.Symbol) unsafe.Pointer {
+	addr := uintptr(symbol.Value + t.slide)
+	return *(*unsafe.Pointer)(unsafe.Pointer(&addr))
+}
+
+// loadSymbolTable 读取可执行文件的符号表与变量的 DWARF 类型。
+var loadSymbolTable = sync.OnceValue(func() *symbolTable {
+	table := &symbolTable{}
+	exe, err := os.Executable()
+	if err != nil {
+		table.err = err
+		return table
+	}
+	file, err := elf.Open(exe)
+	if err != nil {
+		table.err = err
+		return table
+	}
+	defer file.Close()
+	symbols, err := file.Symbols()
+	if err != nil {
+		table.err = fmt.Errorf("cannot read symbol table, build with -ldflags='-s=false': %w", err)
+		return table
+	}
+
+	anchor := runtime.FuncForPC(reflect.ValueOf(os.Executable).Pointer())
+	table.variables = make(map[string]elf.Symbol)
+	for _, v := range symbols {
+		switch {
+		case v.Name == "runtime.types":
+			table.types = v.Value
+		case v.Name == anchor.Name():
+			table.slide = uint64(anchor.Entry()) - v.Value
+		case elf.ST_TYPE(v.Info) == elf.STT_OBJECT:
+			table.variables[v.Name] = v
+		}
+	}
+
+	if table.dwarf, err = file.DWARF(); err != nil {
+		table.dwarf = nil
+		return table
+	}
+	table.varTypes = make(map[string]dwarf.Offset)
+	reader := table.dwarf.Reader()
+	for {
+		entry, err := reader.Next()
+		if err != nil || entry == nil {
+			break
+		}
+		switch entry.Tag {
+		case dwarf.TagCompileUnit:
+		case dwarf.TagVariable:
+			name, _ := entry.Val(dwarf.AttrName).(string)
+			if offset, ok := entry.Val(dwarf.AttrType).(dwarf.Offset); ok {
+				table.varTypes[name] = offset
+			}
+		default:
+			reader.SkipChildren()
+		}
+	}
+	return table
+})
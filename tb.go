@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+import "testing"
+
+// TB 与测试绑定的修改器。修改会在测试结束时按相反顺序自动回退，修改失败时终止测试。
+type TB struct {
+	tb testing.TB
+}
+
+// T 创建与测试绑定的修改器。
+// tb 测试对象，不能是 nil。
+func T(tb testing.TB) *TB {
+	if tb == nil {
+		panic(ErrTargetCannotBeNil)
+	}
+	return &TB{tb: tb}
+}
+
+// Var 同 Var，回退注册到 tb.Cleanup。
+func (t *TB) Var(target, substitute any) Resetter {
+	t.tb.Helper()
+	return bindTB(t.tb, func() Resetter { return Var(target, substitute) })
+}
+
+// FieldByName 同 FieldByName，回退注册到 tb.Cleanup。
+func (t *TB) FieldByName(target any, name string, substitute any) Resetter {
+	t.tb.Helper()
+	return bindTB(t.tb, func() Resetter { return FieldByName(target, name, substitute) })
+}
+
+// Field 同 Field，回退注册到 tb.Cleanup。
+func (t *TB) Field(target any, index int, substitute any) Resetter {
+	t.tb.Helper()
+	return bindTB(t.tb, func() Resetter { return Field(target, index, substitute) })
+}
+
+// Elem 同 Elem，回退注册到 tb.Cleanup。
+func (t *TB) Elem(target any, index int, substitute any) Resetter {
+	t.tb.Helper()
+	return bindTB(t.tb, func() Resetter { return Elem(target, index, substitute) })
+}
+
+// Map 同 Map，回退注册到 tb.Cleanup。
+func (t *TB) Map(target, key any, substitute any) Resetter {
+	t.tb.Helper()
+	return bindTB(t.tb, func() Resetter { return Map(target, key, substitute) })
+}
+
+// MapDelete 同 MapDelete，回退注册到 tb.Cleanup。
+func (t *TB) MapDelete(target, key any) Resetter {
+	t.tb.Helper()
+	return bindTB(t.tb, func() Resetter { return MapDelete(target, key) })
+}
+
+// FuncOuts 同 FuncOuts，回退注册到 tb.Cleanup。
+// 返回值用尽时的错误通过 tb 报告，测试结束回退前将检查调用是否满足期望。
+func (t *TB) FuncOuts(target any, outs []OutValue, opts ...FuncOption) FuncResetter {
+	t.tb.Helper()
+	return t.bindFunc(func(tbOpts []FuncOption) FuncResetter {
+		return FuncOuts(target, outs, append(opts[:len(opts):len(opts)], tbOpts...)...)
+	})
+}
+
+// FuncErr 同 FuncErr，回退注册到 tb.Cleanup，其余同 TB.FuncOuts。
+func (t *TB) FuncErr(target any, err error, times int) FuncResetter {
+	t.tb.Helper()
+	return t.bindFunc(func(opts []FuncOption) FuncResetter { return funcErr(target, err, times, opts) })
+}
+
+// FuncReturns 同 FuncReturns，回退注册到 tb.Cleanup，其余同 TB.FuncOuts。
+func (t *TB) FuncReturns(target any, values ...any) FuncResetter {
+	t.tb.Helper()
+	return t.bindFunc(func(opts []FuncOption) FuncResetter { return funcReturns(target, values, opts) })
+}
+
+// bindFunc 以绑定 tb 的选项替换函数，回退注册到 tb.Cleanup，并在回退前检查调用是否满足期望。
+func (t *TB) bindFunc(fn func(opts []FuncOption) FuncResetter) FuncResetter {
+	t.tb.Helper()
+	r := bindTB(t.tb, func() FuncResetter { return fn([]FuncOption{withTB(t.tb)}) })
+	verifyOnCleanup(t.tb, r)
+	return r
+}
+
+// Func 同 Func，回退注册到 tb.Cleanup。
+func (t *TB) Func(target, replacement any) Resetter {
+	t.tb.Helper()
+	return bindTB(t.tb, func() Resetter { return Func(target, replacement) })
+}
+
+// Snapshot 同 Snapshot，回退注册到 tb.Cleanup。
+func (t *TB) Snapshot(target any) Resetter {
+	t.tb.Helper()
+	return bindTB(t.tb, func() Resetter { return Snapshot(target) })
+}
+
+// Chain 同 Chain，其 Set 和 SetFuncOuts 的回退注册到 tb.Cleanup。
+func (t *TB) Chain(target any, opts ...ChainOption) Chainer {
+	t.tb.Helper()
+	c, err := try(func() Chainer { return Chain(target, opts...) })
+	if err != nil {
+		t.tb.Fatalf("%v", err)
+	}
+	cs := c.(*chainSetter)
+	cs.tb = t.tb
+	return cs
+}
+
+// Path 同 Path，其 Set 和 SetFuncOuts 的回退注册到 tb.Cleanup。
+func (t *TB) Path(target any, expr string, opts ...ChainOption) ChainSetter {
+	t.tb.Helper()
+	c, err := path(target, expr, opts)
+	if err != nil {
+		t.tb.Fatalf("%v", err)
+	}
+	c.tb = t.tb
+	return c
+}
+
+// bindTB 执行修改，失败时终止测试，成功时将回退注册到 tb.Cleanup。
+func bindTB[R Resetter](tb testing.TB, fn func() R) R {
+	tb.Helper()
+	r, err := try(fn)
+	if err != nil {
+		tb.Fatalf("%v", err)
+	}
+	tb.Cleanup(r.Reset)
+	return r
+}
+
+// verifyOnCleanup 在回退前检查函数调用是否满足期望。
+func verifyOnCleanup(tb testing.TB, r interface{ Verify(tb testing.TB) }) {
+	tb.Cleanup(func() { r.Verify(tb) })
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2023 ivfzhou
+ * modify-variables-temporarily is licensed under Mulan PSL v2.
+ * You can use this software according to the terms and conditions of the Mulan PSL v2.
+ * You may obtain a copy of Mulan PSL v2 at:
+ *          http://license.coscl.org.cn/MulanPSL2
+ * THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND,
+ * EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT,
+ * MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+ * See the Mulan PSL v2 for more details.
+ */
+
+package modify_variables_temporarily
+
+// TryVar 同 Var，但以返回值代替 panic 报告错误。
+func TryVar(target, substitute any) (Resetter, error) {
+	return try(func() Resetter { return Var(target, substitute) })
+}
+
+// TryFieldByName 同 FieldByName，但以返回值代替 panic 报告错误。
+func TryFieldByName(target any, name string, substitute any) (Resetter, error) {
+	return try(func() Resetter { return FieldByName(target, name, substitute) })
+}
+
+// TryField 同 Field，但以返回值代替 panic 报告错误。
+func TryField(target any, index int, substitute any) (Resetter, error) {
+	return try(func() Resetter { return Field(target, index, substitute) })
+}
+
+// TryElem 同 Elem，但以返回值代替 panic 报告错误。
+func TryElem(target any, index int, substitute any) (Resetter, error) {
+	return try(func() Resetter { return Elem(target, index, substitute) })
+}
+
+// TryMap 同 Map，但以返回值代替 panic 报告错误。
+func TryMap(target, key any, substitute any) (Resetter, error) {
+	return try(func() Resetter { return Map(target, key, substitute) })
+}
+
+// TryMapDelete 同 MapDelete，但以返回值代替 panic 报告错误。
+func TryMapDelete(target, key any) (Resetter, error) {
+	return try(func() Resetter { return MapDelete(target, key) })
+}
+
+// TryFuncOuts 同 FuncOuts，但以返回值代替 panic 报告错误。
+func TryFuncOuts(target any, outs []OutValue, opts ...FuncOption) (FuncResetter, error) {
+	return try(func() FuncResetter { return FuncOuts(target, outs, opts...) })
+}
+
+// TryFuncErr 同 FuncErr，但以返回值代替 panic 报告错误。
+func TryFuncErr(target any, err error, times int) (FuncResetter, error) {
+	return try(func() FuncResetter { return FuncErr(target, err, times) })
+}
+
+// TryFuncReturns 同 FuncReturns，但以返回值代替 panic 报告错误。
+func TryFuncReturns(target any, values ...any) (FuncResetter, error) {
+	return try(func() FuncResetter { return FuncReturns(target, values...) })
+}
+
+// TryFunc 同 Func，但以返回值代替 panic 报告错误。
+func TryFunc(target, replacement any) (Resetter, error) {
+	return try(func() Resetter { return Func(target, replacement) })
+}
+
+// TrySnapshot 同 Snapshot，但以返回值代替 panic 报告错误。
+func TrySnapshot(target any) (Resetter, error) {
+	return try(func() Resetter { return Snapshot(target) })
+}
+
+// TryChain 同 Chain，但以返回值代替 panic 报告错误。
+func TryChain(target any, opts ...ChainOption) (Chainer, error) {
+	return try(func() Chainer { return Chain(target, opts...) })
+}